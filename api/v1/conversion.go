@@ -0,0 +1,7 @@
+package v1
+
+// v1 is the storage version and the hub of the conversions, the other versions convert to and from it
+
+func (*InferenceConfig) Hub() {}
+
+func (*InferenceRun) Hub() {}
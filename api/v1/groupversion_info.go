@@ -24,4 +24,8 @@ var (
 	GroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: Kind}.String()
 	KindAPIVersion   = Kind + "." + GroupVersion.String()
 	GroupVersionKind = GroupVersion.WithKind(Kind)
+
+	PipelineKind             = reflect.TypeFor[InferencePipeline]().Name()
+	PipelineGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: PipelineKind}.String()
+	PipelineGroupVersionKind = GroupVersion.WithKind(PipelineKind)
 )
@@ -4,11 +4,13 @@ package v1
 import (
 	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
 	prv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 
 type InferenceConfig struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -29,22 +31,211 @@ const (
 type InferenceConfigSpec struct {
 	KServe KServeSpec `json:"kserve,omitempty"`
 	// +kubebuilder:validation:Enum=None;DeleteOnSuccess;DeleteOnCompletion
-	AutoDeletePolicy *AutoDeletePolicy          `json:"autoDeletePolicy"`
+	// +kubebuilder:default=None
+	AutoDeletePolicy *AutoDeletePolicy          `json:"autoDeletePolicy,omitempty"`
 	Storage          StorageSpec                `json:"storage"`
 	Image            string                     `json:"image"`
 	CredentialsRef   *finopsdatatypes.ObjectRef `json:"credentialsRef,omitempty"`
+	// Strategic merge patch applied to the pod template of the runner Jobs and CronJobs.
+	// The runner container is named "inference". Only the labels, the annotations, the nodeSelector, the tolerations,
+	// the affinity and the resources of the runner container can be set.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *v1.PodTemplateSpec `json:"podTemplate,omitempty"`
+	// Service account used by the runner pods, defaults to the runner service account of the controller
+	ServiceAccount *RunnerServiceAccountSpec `json:"serviceAccount,omitempty"`
+	// Environment variables of the runner container. Sensitive values should be read from Secrets with valueFrom
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Secrets and ConfigMaps exposed as environment variables in the runner container
+	EnvFrom []v1.EnvFromSource `json:"envFrom,omitempty"`
+	// Secrets mounted as volumes in the runner container
+	SecretMounts []SecretMount `json:"secretMounts,omitempty"`
+	// Parameters accepted by the runner, used to validate and default the parameters of the InferenceRuns.
+	// When set, InferenceRuns cannot pass parameters not declared here
+	// +listType=map
+	// +listMapKey=name
+	Parameters []ParameterSpec `json:"parameters,omitempty"`
+	// Collection of the logs of the runner container when the executions finish, not collected if not set
+	LogCollection *LogCollectionSpec `json:"logCollection,omitempty"`
+	// Deletion of the Jobs, pods and ConfigMaps of the finished executions and of the finished InferenceRuns,
+	// the fields set on the InferenceRuns take precedence
+	CleanupPolicy *CleanupPolicy `json:"cleanupPolicy,omitempty"`
+}
+
+// CleanupPolicy sets how long the objects of the finished executions are kept. The TTLs are counted from the
+// completion of each execution, fields not set keep the objects until the InferenceRun is deleted.
+// The controller enforces the TTLs of pods, ConfigMaps and InferenceRuns when it reconciles the InferenceRun,
+// so they expire with the precision of the polling interval.
+type CleanupPolicy struct {
+	// TTL of the Jobs and of the Jobs spawned by the CronJob, deleted by Kubernetes with their pods.
+	// Defaults to 300 when autoDeletePolicy is not None. Backfill and matrix Jobs are kept, since they record which
+	// windows and combinations have already run
+	// +kubebuilder:validation:Minimum=0
+	JobTTLSecondsAfterFinished *int32 `json:"jobTTLSecondsAfterFinished,omitempty"`
+	// TTL of the pods of the finished Jobs, the Jobs are kept
+	// +kubebuilder:validation:Minimum=0
+	PodTTLSecondsAfterFinished *int32 `json:"podTTLSecondsAfterFinished,omitempty"`
+	// TTL of the contract ConfigMaps of the finished Jobs. The ConfigMap of the CronJob is kept while the CronJob exists
+	// +kubebuilder:validation:Minimum=0
+	ConfigMapTTLSecondsAfterFinished *int32 `json:"configMapTTLSecondsAfterFinished,omitempty"`
+	// TTL of the InferenceRuns without schedule, counted from the completion of all their Jobs
+	// +kubebuilder:validation:Minimum=0
+	RunTTLSecondsAfterFinished *int32 `json:"runTTLSecondsAfterFinished,omitempty"`
+	// Number of finished InferenceRuns without schedule kept for each InferenceConfig, the oldest ones are deleted
+	// +kubebuilder:validation:Minimum=0
+	KeepLastRuns *int32 `json:"keepLastRuns,omitempty"`
+}
+
+// Merge returns the policy with the fields not set taken from defaults
+func (p *CleanupPolicy) Merge(defaults *CleanupPolicy) CleanupPolicy {
+	merged := CleanupPolicy{}
+	if defaults != nil {
+		merged = *defaults.DeepCopy()
+	}
+	if p == nil {
+		return merged
+	}
+	if p.JobTTLSecondsAfterFinished != nil {
+		merged.JobTTLSecondsAfterFinished = p.JobTTLSecondsAfterFinished
+	}
+	if p.PodTTLSecondsAfterFinished != nil {
+		merged.PodTTLSecondsAfterFinished = p.PodTTLSecondsAfterFinished
+	}
+	if p.ConfigMapTTLSecondsAfterFinished != nil {
+		merged.ConfigMapTTLSecondsAfterFinished = p.ConfigMapTTLSecondsAfterFinished
+	}
+	if p.RunTTLSecondsAfterFinished != nil {
+		merged.RunTTLSecondsAfterFinished = p.RunTTLSecondsAfterFinished
+	}
+	if p.KeepLastRuns != nil {
+		merged.KeepLastRuns = p.KeepLastRuns
+	}
+	return merged
+}
+
+type LogCollectionPolicy string
+
+const (
+	LogCollectionPolicyNever     LogCollectionPolicy = "Never"
+	LogCollectionPolicyOnFailure LogCollectionPolicy = "OnFailure"
+	LogCollectionPolicyAlways    LogCollectionPolicy = "Always"
+)
+
+type LogDestination string
+
+const (
+	// The logs are stored in the execution record of the InferenceRun status, truncated to 4KiB
+	LogDestinationStatus LogDestination = "Status"
+	// The logs are stored in a ConfigMap owned by the InferenceRun, truncated to 512KiB
+	LogDestinationConfigMap LogDestination = "ConfigMap"
+)
+
+type LogCollectionSpec struct {
+	// +kubebuilder:validation:Enum=Never;OnFailure;Always
+	// +kubebuilder:default=OnFailure
+	Policy LogCollectionPolicy `json:"policy,omitempty"`
+	// Number of lines collected from the end of the logs of each runner pod, defaults to 100
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	TailLines *int64 `json:"tailLines,omitempty"`
+	// +kubebuilder:validation:Enum=Status;ConfigMap
+	// +kubebuilder:default=Status
+	Destination LogDestination `json:"destination,omitempty"`
+}
+
+// GetTailLines returns the number of lines to collect, 100 if not set
+func (s *LogCollectionSpec) GetTailLines() int64 {
+	if s.TailLines == nil {
+		return 100
+	}
+	return *s.TailLines
+}
+
+// Collects returns true if the logs of an execution with the result must be collected
+func (s *LogCollectionSpec) Collects(result ExecutionResult) bool {
+	if s == nil {
+		return false
+	}
+	switch s.Policy {
+	case LogCollectionPolicyAlways:
+		return result == ExecutionResultSucceeded || result == ExecutionResultFailed
+	case LogCollectionPolicyNever:
+		return false
+	default:
+		return result == ExecutionResultFailed
+	}
+}
+
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeNumber  ParameterType = "number"
+	ParameterTypeBoolean ParameterType = "boolean"
+)
+
+// ParameterSpec declares a parameter of the runner. Values are passed as strings in the contract, the type only
+// constrains their format.
+type ParameterSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// +kubebuilder:validation:Enum=string;integer;number;boolean
+	// +kubebuilder:default=string
+	Type ParameterType `json:"type,omitempty"`
+	// Required parameters without a default must be set by the InferenceRuns
+	Required bool `json:"required,omitempty"`
+	// Value used when the InferenceRun does not set the parameter, it can be a template
+	Default *string `json:"default,omitempty"`
+	// Allowed values
+	Enum []string `json:"enum,omitempty"`
+	// Regular expression the values must match
+	Pattern string `json:"pattern,omitempty"`
+}
+
+type SecretMount struct {
+	SecretName string `json:"secretName"`
+	// Directory where the secret is mounted, it cannot be the directory of the contract (/tmp)
+	MountPath string `json:"mountPath"`
+	// Keys of the secret to mount, all keys if empty
+	Items []v1.KeyToPath `json:"items,omitempty"`
+}
+
+type RunnerServiceAccountSpec struct {
+	// Name of the service account. If empty and create is true, the name is <InferenceConfig name>-runner.
+	// Existing service accounts (create false) must be labeled ai.krateo.io/runner-service-account=true
+	Name string `json:"name,omitempty"`
+	// If true, the controller creates the service account with a Role and RoleBinding
+	// that only allow to read the secrets referenced by the InferenceConfig, which must be in its namespace
+	Create bool `json:"create,omitempty"`
 }
 
 type KServeSpec struct {
-	ModelName      string `json:"modelName,omitempty"`
-	ModelUrl       string `json:"modelUrl,omitempty"`
+	ModelName string `json:"modelName,omitempty"`
+	ModelUrl  string `json:"modelUrl,omitempty"`
+	// +kubebuilder:default=v2
 	ModelVersion   string `json:"modelVersion,omitempty"`
 	ModelInputName string `json:"modelInputName,omitempty"`
 }
 
+type OutputFormat string
+
+const (
+	OutputFormatJSON    OutputFormat = "JSON"
+	OutputFormatCSV     OutputFormat = "CSV"
+	OutputFormatNDJSON  OutputFormat = "NDJSON"
+	OutputFormatParquet OutputFormat = "Parquet"
+	OutputFormatArrow   OutputFormat = "Arrow"
+)
+
 type StorageSpec struct {
 	Input  StorageMap `json:"input,omitempty"`
 	Output StorageMap `json:"output,omitempty"`
+	// Format used by the runner to encode the predictions sent to the output storage
+	// +kubebuilder:validation:Enum=JSON;CSV;NDJSON;Parquet;Arrow
+	// +kubebuilder:default=JSON
+	OutputFormat OutputFormat `json:"outputFormat,omitempty"`
 }
 
 type InferenceConfigStatus struct {
@@ -70,3 +261,36 @@ func (mg *InferenceConfig) GetCondition(ct prv1.ConditionType) prv1.Condition {
 func (mg *InferenceConfig) SetConditions(c ...prv1.Condition) {
 	mg.Status.SetConditions(c...)
 }
+
+// GetRunnerServiceAccountName returns the service account for the runner pods, empty to use the default one
+func (mg *InferenceConfig) GetRunnerServiceAccountName() string {
+	if mg.Spec.ServiceAccount == nil {
+		return ""
+	}
+	if mg.Spec.ServiceAccount.Name != "" {
+		return mg.Spec.ServiceAccount.Name
+	}
+	if mg.Spec.ServiceAccount.Create {
+		return mg.Name + "-runner"
+	}
+	return ""
+}
+
+// GetAutoDeletePolicy returns the auto delete policy, None if not set
+func (mg *InferenceConfig) GetAutoDeletePolicy() AutoDeletePolicy {
+	if mg.Spec.AutoDeletePolicy == nil {
+		return AutoDeletePolicyNone
+	}
+	return *mg.Spec.AutoDeletePolicy
+}
+
+// Default sets the defaults of the fields not set, as the defaulting webhook does
+func (mg *InferenceConfig) Default() {
+	if mg.Spec.AutoDeletePolicy == nil {
+		policy := AutoDeletePolicyNone
+		mg.Spec.AutoDeletePolicy = &policy
+	}
+	if mg.Spec.KServe.ModelVersion == "" {
+		mg.Spec.KServe.ModelVersion = "v2"
+	}
+}
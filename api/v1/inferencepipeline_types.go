@@ -0,0 +1,101 @@
+// +kubebuilder:object:generate=true
+package v1
+
+import (
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	prv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+type InferencePipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferencePipelineSpec   `json:"spec,omitempty"`
+	Status InferencePipelineStatus `json:"status,omitempty"`
+}
+
+type InferencePipelineSpec struct {
+	// Steps of the pipeline, run in the order given by their dependencies
+	// +kubebuilder:validation:MinItems=1
+	Steps []PipelineStep `json:"steps"`
+	// Parameters passed to all the steps, step parameters take precedence
+	Parameters *map[string]string `json:"parameters,omitempty"`
+}
+
+// PipelineStep runs either an InferenceConfig, through a child InferenceRun, or a generic container
+type PipelineStep struct {
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	Name string `json:"name"`
+	// Steps that must succeed before this step starts
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// InferenceConfig run by the step, the namespace defaults to the one of the pipeline
+	ConfigRef *finopsdatatypes.ObjectRef `json:"configRef,omitempty"`
+	// Generic container run by the step, the contract is mounted in /tmp/contract.json
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Container *v1.Container `json:"container,omitempty"`
+	// Step whose output storage is used as input storage of this step, it implies a dependency
+	InputFrom string `json:"inputFrom,omitempty"`
+	// Output storage of the step, for InferenceConfig steps it overrides the output storage of the InferenceConfig
+	Output StorageMap `json:"output,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int                `json:"timeoutSeconds,omitempty"`
+	Parameters     *map[string]string `json:"parameters,omitempty"`
+}
+
+type PipelinePhase string
+
+const (
+	PipelinePhasePending   PipelinePhase = "Pending"
+	PipelinePhaseRunning   PipelinePhase = "Running"
+	PipelinePhaseSucceeded PipelinePhase = "Succeeded"
+	PipelinePhaseFailed    PipelinePhase = "Failed"
+	// Only for steps, when a step they depend on failed
+	PipelinePhaseSkipped PipelinePhase = "Skipped"
+)
+
+type InferencePipelineStatus struct {
+	prv1.ConditionedStatus `json:",inline"`
+	Phase                  PipelinePhase        `json:"phase,omitempty"`
+	StartTime              *metav1.Time         `json:"startTime,omitempty"`
+	CompletionTime         *metav1.Time         `json:"completionTime,omitempty"`
+	Steps                  []PipelineStepStatus `json:"steps,omitempty"`
+}
+
+type PipelineStepStatus struct {
+	Name  string        `json:"name"`
+	Phase PipelinePhase `json:"phase"`
+	// InferenceRun or Job created for the step
+	Resource       *v1.ObjectReference `json:"resource,omitempty"`
+	StartTime      *metav1.Time        `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time        `json:"completionTime,omitempty"`
+	Message        string              `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+type InferencePipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InferencePipeline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InferencePipeline{}, &InferencePipelineList{})
+}
+
+func (mg *InferencePipeline) GetCondition(ct prv1.ConditionType) prv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+func (mg *InferencePipeline) SetConditions(c ...prv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
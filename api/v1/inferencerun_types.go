@@ -4,12 +4,14 @@ package v1
 import (
 	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
 	prv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 
 type InferenceRun struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -22,16 +24,175 @@ type InferenceRun struct {
 type InferenceRunSpec struct {
 	ConfigRef      *finopsdatatypes.ObjectRef `json:"configRef"`
 	TimeoutSeconds int                        `json:"timeoutSeconds"`
-	Parameters     *map[string]string         `json:"parameters,omitempty"`
+	// Values can be templates, e.g. {{ .ScheduledTime | addDays -1 | date "2006-01-02" }}
+	Parameters *map[string]string `json:"parameters,omitempty"`
+	// Parameters set from keys of ConfigMaps and Secrets in the namespace of the InferenceRun
+	ParametersFrom []ParameterSource `json:"parametersFrom,omitempty"`
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern="(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((((\\d+,)+\\d+|(\\d+(\\/|-)\\d+)|\\d+|\\*) ?){5,7})"
 	Schedule *string `json:"schedule,omitempty"`
+	// Scheduling controls of the CronJob, only used when schedule is set
+	Scheduling *SchedulingSpec `json:"scheduling,omitempty"`
+	// How changes to the InferenceRun and to the InferenceConfig are applied to the existing Job or CronJob (default: InPlace)
+	// +kubebuilder:validation:Enum=InPlace;Recreate;Never
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+	// Number of executions kept in status.history (default: 10)
+	// +kubebuilder:validation:Minimum=0
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+	// Runs the inference once for each interval of a past time range
+	Backfill *BackfillSpec `json:"backfill,omitempty"`
+	// Runs the inference once for each combination of parameter values, not supported with schedule and backfill
+	Matrix *MatrixSpec `json:"matrix,omitempty"`
+	// Runs the Jobs as Indexed Jobs, where each pod processes a shard of the input rows
+	Sharding *ShardingSpec `json:"sharding,omitempty"`
+	// Storage used instead of the storage of the InferenceConfig, e.g. to chain the steps of a pipeline
+	StorageOverride *StorageOverride `json:"storageOverride,omitempty"`
+	// Overrides the fields of the cleanup policy of the InferenceConfig
+	CleanupPolicy *CleanupPolicy `json:"cleanupPolicy,omitempty"`
+}
+
+// StorageOverride replaces the input and the output storage of the InferenceConfig, when not empty.
+// Secrets referenced here must be readable by the runner service account.
+type StorageOverride struct {
+	Input  StorageMap `json:"input,omitempty"`
+	Output StorageMap `json:"output,omitempty"`
+}
+
+type ShardingSpec struct {
+	// Number of shards, i.e. completions of the Indexed Job
+	// +kubebuilder:validation:Minimum=1
+	Shards int32 `json:"shards"`
+	// Maximum number of shards processed at the same time (default: shards)
+	// +kubebuilder:validation:Minimum=1
+	Parallelism *int32 `json:"parallelism,omitempty"`
+}
+
+// MatrixSpec expands into one Job for each combination of the values of its parameters, which override the
+// parameters of the InferenceRun. Adding values creates Jobs only for the new combinations.
+type MatrixSpec struct {
+	// Values of each parameter
+	Parameters map[string][]string `json:"parameters,omitempty"`
+	// Values of parameters read from keys of ConfigMaps, as a JSON array or one value per line.
+	// Secrets are not supported, since the values are copied in the contract of the Jobs
+	ParametersFrom []ParameterSource `json:"parametersFrom,omitempty"`
+	// Maximum number of Jobs running at the same time (default: 1)
+	// +kubebuilder:validation:Minimum=1
+	MaxParallelism *int32 `json:"maxParallelism,omitempty"`
+}
+
+type MatrixStatus struct {
+	Combinations   int32        `json:"combinations"`
+	Active         int32        `json:"active,omitempty"`
+	Succeeded      int32        `json:"succeeded,omitempty"`
+	Failed         int32        `json:"failed,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// BackfillSpec splits the time range [start, end) in windows of the given interval and creates one Job per window.
+// Changing start, end or interval starts a new backfill.
+type BackfillSpec struct {
+	Start metav1.Time `json:"start"`
+	End   metav1.Time `json:"end"`
+	// Length of each window, e.g. 24h
+	Interval metav1.Duration `json:"interval"`
+	// Maximum number of Jobs running at the same time (default: 1)
+	// +kubebuilder:validation:Minimum=1
+	MaxParallelism *int32 `json:"maxParallelism,omitempty"`
+}
+
+type BackfillStatus struct {
+	// Hash of start, end and interval of the backfill the status refers to
+	Id             string       `json:"id"`
+	Windows        int32        `json:"windows"`
+	Active         int32        `json:"active,omitempty"`
+	Succeeded      int32        `json:"succeeded,omitempty"`
+	Failed         int32        `json:"failed,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ParameterSource sets a parameter from a key of a ConfigMap or of a Secret.
+// ConfigMap values are copied in the contract, Secret values are only exposed to the runner as environment variables.
+type ParameterSource struct {
+	Name            string                   `json:"name"`
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *v1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+type UpdatePolicy string
+
+const (
+	// CronJobs are updated in place, one-shot Jobs are left unchanged since their template is immutable
+	UpdatePolicyInPlace UpdatePolicy = "InPlace"
+	// CronJobs are updated in place, one-shot Jobs are deleted and recreated, even if running
+	UpdatePolicyRecreate UpdatePolicy = "Recreate"
+	// Changes are never applied to existing Jobs and CronJobs, except for the scheduling controls
+	UpdatePolicyNever UpdatePolicy = "Never"
+)
+
+// SchedulingSpec mirrors the scheduling fields of the CronJob spec. Unset fields use the Kubernetes defaults.
+type SchedulingSpec struct {
+	// Time zone name for the schedule, e.g. Europe/Rome (default: time zone of the kube-controller-manager)
+	TimeZone *string `json:"timeZone,omitempty"`
+	// How to treat concurrent executions (default: Allow)
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy v1batch.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Deadline in seconds for starting the job if it misses its scheduled time
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Suspends subsequent executions, it does not apply to already started executions (default: false)
+	Suspend *bool `json:"suspend,omitempty"`
+	// Number of successful finished jobs to retain (default: 3)
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// Number of failed finished jobs to retain (default: 1)
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
 }
 
 type InferenceRunStatus struct {
 	prv1.ConditionedStatus `json:",inline"`
 	Contract               []byte              `json:"contract,omitempty"`
 	JobStatus              *v1.ObjectReference `json:"jobStatus,omitempty"`
+	// Generation of the InferenceConfig used to compute the contract
+	ConfigGeneration int64 `json:"configGeneration,omitempty"`
+	// Last executions of the InferenceRun, most recent first
+	History            []ExecutionRecord `json:"history,omitempty"`
+	LastSuccessfulTime *metav1.Time      `json:"lastSuccessfulTime,omitempty"`
+	LastFailureTime    *metav1.Time      `json:"lastFailureTime,omitempty"`
+	// Result and completion time of the Job of the InferenceRun without schedule, kept when its record leaves the history
+	Result         ExecutionResult `json:"result,omitempty"`
+	CompletionTime *metav1.Time    `json:"completionTime,omitempty"`
+	// Value of the ai.krateo.io/trigger-at annotation of the last on-demand execution
+	LastTriggerAt string          `json:"lastTriggerAt,omitempty"`
+	Backfill      *BackfillStatus `json:"backfill,omitempty"`
+	Matrix        *MatrixStatus   `json:"matrix,omitempty"`
+	// Errors of the validation of the parameters against the schema of the InferenceConfig, no Job is run while set
+	ParameterErrors []string `json:"parameterErrors,omitempty"`
+}
+
+type ExecutionResult string
+
+const (
+	ExecutionResultPending   ExecutionResult = "Pending"
+	ExecutionResultRunning   ExecutionResult = "Running"
+	ExecutionResultSucceeded ExecutionResult = "Succeeded"
+	ExecutionResultFailed    ExecutionResult = "Failed"
+)
+
+// ExecutionRecord is the outcome of a single Job of the InferenceRun
+type ExecutionRecord struct {
+	JobName        string          `json:"jobName"`
+	StartTime      *metav1.Time    `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time    `json:"completionTime,omitempty"`
+	Result         ExecutionResult `json:"result"`
+	// Reason and message of the Failed condition of the Job
+	FailureReason string `json:"failureReason,omitempty"`
+	// Termination message of the runner container, truncated
+	ResultSummary string `json:"resultSummary,omitempty"`
+	// Tail of the logs of the runner container, when collected in the status by the logCollection of the InferenceConfig
+	Logs string `json:"logs,omitempty"`
+	// ConfigMap with the tail of the logs of the runner container, when collected in a ConfigMap
+	LogsConfigMap string `json:"logsConfigMap,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -53,3 +214,53 @@ func (mg *InferenceRun) GetCondition(ct prv1.ConditionType) prv1.Condition {
 func (mg *InferenceRun) SetConditions(c ...prv1.Condition) {
 	mg.Status.SetConditions(c...)
 }
+
+// Default sets the namespace of the referenced InferenceConfig, when not set, and the timeout, when zero
+func (mg *InferenceRun) Default(defaultTimeoutSeconds int) {
+	if mg.Spec.ConfigRef != nil && mg.Spec.ConfigRef.Namespace == "" {
+		mg.Spec.ConfigRef.Namespace = mg.Namespace
+	}
+	if mg.Spec.TimeoutSeconds == 0 {
+		mg.Spec.TimeoutSeconds = defaultTimeoutSeconds
+	}
+}
+
+func (s *InferenceRunSpec) GetUpdatePolicy() UpdatePolicy {
+	if s.UpdatePolicy == "" {
+		return UpdatePolicyInPlace
+	}
+	return s.UpdatePolicy
+}
+
+func (s *InferenceRunSpec) GetHistoryLimit() int {
+	if s.HistoryLimit == nil {
+		return 10
+	}
+	return int(*s.HistoryLimit)
+}
+
+// GetCleanupPolicy returns the cleanup policy of the InferenceRun merged with the one of the InferenceConfig
+func (mg *InferenceRun) GetCleanupPolicy(iConf *InferenceConfig) CleanupPolicy {
+	return mg.Spec.CleanupPolicy.Merge(iConf.Spec.CleanupPolicy)
+}
+
+func (s *BackfillSpec) GetMaxParallelism() int32 {
+	if s.MaxParallelism == nil {
+		return 1
+	}
+	return *s.MaxParallelism
+}
+
+func (s *MatrixSpec) GetMaxParallelism() int32 {
+	if s.MaxParallelism == nil {
+		return 1
+	}
+	return *s.MaxParallelism
+}
+
+func (s *ShardingSpec) GetParallelism() int32 {
+	if s.Parallelism == nil {
+		return s.Shards
+	}
+	return *s.Parallelism
+}
@@ -6,6 +6,8 @@ import (
 	"kserve-controller/internal/helpers/storage"
 	"kserve-controller/internal/helpers/storage/providers"
 
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -18,10 +20,11 @@ func (r *InferenceConfig) GetStorageProvider() (storage.StorageInterface, storag
 	for spec, rawExt := range r.Spec.Storage.Input {
 		switch spec {
 		case storage.KrateoStorage:
-			var input providers.KrateoStorage
-			if err := json.Unmarshal(rawExt.Raw, &input); err != nil {
+			var krateoInput providers.KrateoStorage
+			if err := json.Unmarshal(rawExt.Raw, &krateoInput); err != nil {
 				return nil, nil, fmt.Errorf("failed to unmarshal krateo storage: %w", err)
 			}
+			input = krateoInput
 		// Add other storage providers here
 		default:
 			input = rawExt
@@ -31,10 +34,11 @@ func (r *InferenceConfig) GetStorageProvider() (storage.StorageInterface, storag
 	for spec, rawExt := range r.Spec.Storage.Output {
 		switch spec {
 		case storage.KrateoStorage:
-			var output providers.KrateoStorage
-			if err := json.Unmarshal(rawExt.Raw, &output); err != nil {
+			var krateoOutput providers.KrateoStorage
+			if err := json.Unmarshal(rawExt.Raw, &krateoOutput); err != nil {
 				return nil, nil, fmt.Errorf("failed to unmarshal krateo storage: %w", err)
 			}
+			output = krateoOutput
 		// Add other storage providers here
 		default:
 			output = rawExt
@@ -44,3 +48,57 @@ func (r *InferenceConfig) GetStorageProvider() (storage.StorageInterface, storag
 	return input, output, nil
 
 }
+
+// GetReferencedSecrets returns the secrets referenced by the InferenceConfig: the endpoints of the storage providers and the image pull credentials.
+// Secrets without a namespace are in the namespace of the InferenceConfig.
+func (r *InferenceConfig) GetReferencedSecrets() ([]finopsdatatypes.ObjectRef, error) {
+	input, output, err := r.GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []finopsdatatypes.ObjectRef{}
+	addSecret := func(ref *finopsdatatypes.ObjectRef) {
+		if ref == nil || ref.Name == "" {
+			return
+		}
+		secret := *ref
+		if secret.Namespace == "" {
+			secret.Namespace = r.Namespace
+		}
+		for _, s := range secrets {
+			if s == secret {
+				return
+			}
+		}
+		secrets = append(secrets, secret)
+	}
+
+	for _, provider := range []storage.StorageInterface{input, output} {
+		switch p := provider.(type) {
+		case providers.KrateoStorage:
+			addSecret(p.Api.EndpointRef)
+			// Add other storage providers here
+		}
+	}
+	addSecret(r.Spec.CredentialsRef)
+
+	return secrets, nil
+}
+
+// GetForeignSecrets returns the secrets referenced by the InferenceConfig outside of its namespace. The controller
+// does not grant access to them to the runner service accounts it creates, since the author of the InferenceConfig
+// may not be allowed to read them.
+func (r *InferenceConfig) GetForeignSecrets() ([]finopsdatatypes.ObjectRef, error) {
+	secrets, err := r.GetReferencedSecrets()
+	if err != nil {
+		return nil, err
+	}
+	foreign := []finopsdatatypes.ObjectRef{}
+	for _, secret := range secrets {
+		if secret.Namespace != r.Namespace {
+			foreign = append(foreign, secret)
+		}
+	}
+	return foreign, nil
+}
@@ -0,0 +1,254 @@
+package v1beta2
+
+import (
+	"testing"
+	"time"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/utils/ptr"
+
+	controllerapiv1 "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/storage"
+)
+
+func testStorage() controllerapiv1.StorageSpec {
+	return controllerapiv1.StorageSpec{
+		Input: controllerapiv1.StorageMap{
+			storage.KrateoStorage: runtime.RawExtension{Raw: []byte(`{"api":{"path":"/compute/kserveinput","verb":"POST"}}`)},
+		},
+		Output: controllerapiv1.StorageMap{
+			storage.KrateoStorage: runtime.RawExtension{Raw: []byte(`{"api":{"path":"/compute/kserveoutput","verb":"POST"}}`)},
+		},
+		OutputFormat: controllerapiv1.OutputFormatCSV,
+	}
+}
+
+func testObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        "forecast",
+		Namespace:   "krateo-system",
+		Labels:      map[string]string{"app": "forecast"},
+		Annotations: map[string]string{"ai.krateo.io/trigger-at": "2026-01-01T00:00:00Z"},
+		Generation:  3,
+	}
+}
+
+func TestInferenceConfigRoundTrip(t *testing.T) {
+	policy := controllerapiv1.AutoDeletePolicyDeleteOnSuccess
+
+	tests := map[string]*controllerapiv1.InferenceConfig{
+		"empty": {},
+		"full": {
+			ObjectMeta: testObjectMeta(),
+			Spec: controllerapiv1.InferenceConfigSpec{
+				KServe: controllerapiv1.KServeSpec{
+					ModelName:      "sklearn-iris",
+					ModelUrl:       "sklearn-iris-predictor.kserve-test.svc.cluster.local/v2/models/sklearn-iris/infer",
+					ModelVersion:   "v2",
+					ModelInputName: "input-0",
+				},
+				AutoDeletePolicy: &policy,
+				Storage:          testStorage(),
+				Image:            "ghcr.io/krateoplatformops/kserve-krateo-runner:0.1.0",
+				CredentialsRef:   &finopsdatatypes.ObjectRef{Name: "credentials", Namespace: "krateo-system"},
+				PodTemplate: &v1.PodTemplateSpec{
+					Spec: v1.PodSpec{NodeSelector: map[string]string{"gpu": "true"}},
+				},
+				ServiceAccount: &controllerapiv1.RunnerServiceAccountSpec{Create: true},
+				Env:            []v1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+				EnvFrom: []v1.EnvFromSource{
+					{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "tokens"}}},
+				},
+				SecretMounts: []controllerapiv1.SecretMount{{SecretName: "certs", MountPath: "/etc/certs"}},
+				Parameters: []controllerapiv1.ParameterSpec{
+					{Name: "input_table_name", Required: true, Pattern: "^[a-z_]+$"},
+					{Name: "horizon", Type: controllerapiv1.ParameterTypeInteger, Default: ptr.To("24"), Enum: []string{"24", "168"}},
+				},
+				LogCollection: &controllerapiv1.LogCollectionSpec{
+					Policy:      controllerapiv1.LogCollectionPolicyAlways,
+					TailLines:   ptr.To(int64(200)),
+					Destination: controllerapiv1.LogDestinationConfigMap,
+				},
+				CleanupPolicy: &controllerapiv1.CleanupPolicy{
+					JobTTLSecondsAfterFinished:       ptr.To(int32(3600)),
+					ConfigMapTTLSecondsAfterFinished: ptr.To(int32(600)),
+					KeepLastRuns:                     ptr.To(int32(20)),
+				},
+			},
+			Status: controllerapiv1.InferenceConfigStatus{},
+		},
+	}
+
+	for name, hub := range tests {
+		t.Run(name, func(t *testing.T) {
+			spoke := &InferenceConfig{}
+			if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom: %v", err)
+			}
+			got := &controllerapiv1.InferenceConfig{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(hub, got) {
+				t.Errorf("v1 -> v1beta2 -> v1 is not lossless:\n%s", diff.Diff(hub, got))
+			}
+
+			back := &InferenceConfig{}
+			if err := back.ConvertFrom(got); err != nil {
+				t.Fatalf("ConvertFrom: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(spoke, back) {
+				t.Errorf("v1beta2 -> v1 -> v1beta2 is not lossless:\n%s", diff.Diff(spoke, back))
+			}
+		})
+	}
+}
+
+func TestInferenceRunRoundTrip(t *testing.T) {
+	tests := map[string]*controllerapiv1.InferenceRun{
+		"empty": {},
+		"one-shot": {
+			ObjectMeta: testObjectMeta(),
+			Spec: controllerapiv1.InferenceRunSpec{
+				ConfigRef:      &finopsdatatypes.ObjectRef{Name: "forecast"},
+				TimeoutSeconds: 1800,
+				Parameters: &map[string]string{
+					"input_table_name": "kserve_controller_input",
+					"date":             `{{ .ScheduledTime | date "2006-01-02" }}`,
+				},
+				ParametersFrom: []controllerapiv1.ParameterSource{
+					{
+						Name: "region",
+						ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "regions"},
+							Key:                  "default",
+						},
+					},
+					{
+						Name: "token",
+						SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "tokens"},
+							Key:                  "token",
+							Optional:             ptr.To(true),
+						},
+					},
+				},
+				UpdatePolicy:    controllerapiv1.UpdatePolicyRecreate,
+				HistoryLimit:    ptr.To(int32(5)),
+				Sharding:        &controllerapiv1.ShardingSpec{Shards: 8, Parallelism: ptr.To(int32(4))},
+				StorageOverride: &controllerapiv1.StorageOverride{Output: testStorage().Output},
+				Matrix: &controllerapiv1.MatrixSpec{
+					Parameters:     map[string][]string{"key_value": {"vm-01", "vm-02"}},
+					MaxParallelism: ptr.To(int32(2)),
+				},
+				CleanupPolicy: &controllerapiv1.CleanupPolicy{
+					PodTTLSecondsAfterFinished: ptr.To(int32(0)),
+					RunTTLSecondsAfterFinished: ptr.To(int32(86400)),
+				},
+			},
+			Status: controllerapiv1.InferenceRunStatus{
+				Contract:         []byte(`{"jobId":"1234"}`),
+				ConfigGeneration: 2,
+				History: []controllerapiv1.ExecutionRecord{
+					{JobName: "inf-forecast-1234", Result: controllerapiv1.ExecutionResultSucceeded, ResultSummary: "stored 96 predictions"},
+					{JobName: "inf-forecast-5678", Result: controllerapiv1.ExecutionResultFailed, FailureReason: "BackoffLimitExceeded: Job has reached the specified backoff limit", Logs: "connection refused"},
+				},
+			},
+		},
+		"scheduled": {
+			ObjectMeta: testObjectMeta(),
+			Spec: controllerapiv1.InferenceRunSpec{
+				ConfigRef: &finopsdatatypes.ObjectRef{Name: "forecast", Namespace: "krateo-system"},
+				Schedule:  ptr.To("0 2 * * *"),
+				Scheduling: &controllerapiv1.SchedulingSpec{
+					TimeZone:                   ptr.To("Europe/Rome"),
+					ConcurrencyPolicy:          v1batch.ForbidConcurrent,
+					StartingDeadlineSeconds:    ptr.To(int64(600)),
+					Suspend:                    ptr.To(false),
+					SuccessfulJobsHistoryLimit: ptr.To(int32(3)),
+					FailedJobsHistoryLimit:     ptr.To(int32(1)),
+				},
+				Backfill: &controllerapiv1.BackfillSpec{
+					Start:    metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+					End:      metav1.NewTime(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)),
+					Interval: metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+		},
+		"schedule without scheduling": {
+			Spec: controllerapiv1.InferenceRunSpec{
+				ConfigRef: &finopsdatatypes.ObjectRef{Name: "forecast"},
+				Schedule:  ptr.To("@daily"),
+			},
+		},
+		"scheduling without schedule": {
+			Spec: controllerapiv1.InferenceRunSpec{
+				ConfigRef:  &finopsdatatypes.ObjectRef{Name: "forecast"},
+				Scheduling: &controllerapiv1.SchedulingSpec{Suspend: ptr.To(true)},
+			},
+		},
+	}
+
+	for name, hub := range tests {
+		t.Run(name, func(t *testing.T) {
+			spoke := &InferenceRun{}
+			if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom: %v", err)
+			}
+			got := &controllerapiv1.InferenceRun{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(hub, got) {
+				t.Errorf("v1 -> v1beta2 -> v1 is not lossless:\n%s", diff.Diff(hub, got))
+			}
+
+			back := &InferenceRun{}
+			if err := back.ConvertFrom(got); err != nil {
+				t.Fatalf("ConvertFrom: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(spoke, back) {
+				t.Errorf("v1beta2 -> v1 -> v1beta2 is not lossless:\n%s", diff.Diff(spoke, back))
+			}
+		})
+	}
+}
+
+func TestInferenceRunConvertTo(t *testing.T) {
+	spoke := &InferenceRun{
+		Spec: InferenceRunSpec{
+			ConfigRef: &finopsdatatypes.ObjectRef{Name: "forecast"},
+			Parameters: []Parameter{
+				{Name: "horizon", Value: "24"},
+				{Name: "token", ValueFrom: &ParameterValueSource{
+					SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "tokens"}, Key: "token"},
+				}},
+			},
+			Execution: ExecutionSpec{Timeout: &metav1.Duration{Duration: 90*time.Second + 500*time.Millisecond}},
+			Schedule:  &ScheduleSpec{Cron: "*/5 * * * *"},
+		},
+	}
+
+	hub := &controllerapiv1.InferenceRun{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo: %v", err)
+	}
+	if hub.Spec.TimeoutSeconds != 90 {
+		t.Errorf("timeout is not rounded down to seconds: got %d, want 90", hub.Spec.TimeoutSeconds)
+	}
+	if hub.Spec.Parameters == nil || (*hub.Spec.Parameters)["horizon"] != "24" || len(*hub.Spec.Parameters) != 1 {
+		t.Errorf("unexpected parameters: %v", hub.Spec.Parameters)
+	}
+	if len(hub.Spec.ParametersFrom) != 1 || hub.Spec.ParametersFrom[0].Name != "token" || hub.Spec.ParametersFrom[0].SecretKeyRef == nil {
+		t.Errorf("unexpected parametersFrom: %v", hub.Spec.ParametersFrom)
+	}
+	if hub.Spec.Schedule == nil || *hub.Spec.Schedule != "*/5 * * * *" || hub.Spec.Scheduling != nil {
+		t.Errorf("unexpected schedule: %v, scheduling: %v", hub.Spec.Schedule, hub.Spec.Scheduling)
+	}
+}
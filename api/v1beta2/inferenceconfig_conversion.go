@@ -0,0 +1,71 @@
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	controllerapiv1 "kserve-controller/api/v1"
+)
+
+// ConvertTo converts this InferenceConfig to the hub version (v1)
+func (src *InferenceConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controllerapiv1.InferenceConfig)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	spec := src.DeepCopy().Spec
+	dst.Spec = controllerapiv1.InferenceConfigSpec{
+		KServe: controllerapiv1.KServeSpec{
+			ModelName:      spec.Model.Name,
+			ModelUrl:       spec.Model.URL,
+			ModelVersion:   spec.Model.Version,
+			ModelInputName: spec.Model.InputName,
+		},
+		AutoDeletePolicy: spec.AutoDeletePolicy,
+		Storage:          spec.Storage,
+		Image:            spec.Runner.Image,
+		CredentialsRef:   spec.Runner.CredentialsRef,
+		PodTemplate:      spec.Runner.PodTemplate,
+		ServiceAccount:   spec.Runner.ServiceAccount,
+		Env:              spec.Runner.Env,
+		EnvFrom:          spec.Runner.EnvFrom,
+		SecretMounts:     spec.Runner.SecretMounts,
+		Parameters:       spec.Parameters,
+		LogCollection:    spec.Runner.LogCollection,
+		CleanupPolicy:    spec.CleanupPolicy,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *InferenceConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controllerapiv1.InferenceConfig)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	spec := src.DeepCopy().Spec
+	dst.Spec = InferenceConfigSpec{
+		Model: ModelSpec{
+			Name:      spec.KServe.ModelName,
+			URL:       spec.KServe.ModelUrl,
+			Version:   spec.KServe.ModelVersion,
+			InputName: spec.KServe.ModelInputName,
+		},
+		Runner: RunnerSpec{
+			Image:          spec.Image,
+			CredentialsRef: spec.CredentialsRef,
+			PodTemplate:    spec.PodTemplate,
+			ServiceAccount: spec.ServiceAccount,
+			Env:            spec.Env,
+			EnvFrom:        spec.EnvFrom,
+			SecretMounts:   spec.SecretMounts,
+			LogCollection:  spec.LogCollection,
+		},
+		AutoDeletePolicy: spec.AutoDeletePolicy,
+		Storage:          spec.Storage,
+		Parameters:       spec.Parameters,
+		CleanupPolicy:    spec.CleanupPolicy,
+	}
+	return nil
+}
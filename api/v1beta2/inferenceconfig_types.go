@@ -0,0 +1,87 @@
+// +kubebuilder:object:generate=true
+package v1beta2
+
+import (
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	controllerapiv1 "kserve-controller/api/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+
+// InferenceConfig is served only when the conversion webhook is enabled: the Helm chart serves the version and
+// configures the conversion of the CRD when the webhooks are enabled
+type InferenceConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferenceConfigSpec                   `json:"spec,omitempty"`
+	Status controllerapiv1.InferenceConfigStatus `json:"status,omitempty"`
+}
+
+type InferenceConfigSpec struct {
+	// KServe model called by the runner
+	Model ModelSpec `json:"model,omitempty"`
+	// Runner Jobs of the InferenceRuns
+	Runner RunnerSpec `json:"runner"`
+	// +kubebuilder:validation:Enum=None;DeleteOnSuccess;DeleteOnCompletion
+	// +kubebuilder:default=None
+	AutoDeletePolicy *controllerapiv1.AutoDeletePolicy `json:"autoDeletePolicy,omitempty"`
+	Storage          controllerapiv1.StorageSpec       `json:"storage"`
+	// Parameters accepted by the runner, used to validate and default the parameters of the InferenceRuns.
+	// When set, InferenceRuns cannot pass parameters not declared here
+	// +listType=map
+	// +listMapKey=name
+	Parameters []controllerapiv1.ParameterSpec `json:"parameters,omitempty"`
+	// Deletion of the Jobs, pods and ConfigMaps of the finished executions and of the finished InferenceRuns,
+	// the fields set on the InferenceRuns take precedence
+	CleanupPolicy *controllerapiv1.CleanupPolicy `json:"cleanupPolicy,omitempty"`
+}
+
+type ModelSpec struct {
+	Name string `json:"name,omitempty"`
+	// Inference endpoint of the model
+	URL string `json:"url,omitempty"`
+	// Version of the KServe inference protocol
+	// +kubebuilder:default=v2
+	Version   string `json:"version,omitempty"`
+	InputName string `json:"inputName,omitempty"`
+}
+
+type RunnerSpec struct {
+	Image          string                     `json:"image"`
+	CredentialsRef *finopsdatatypes.ObjectRef `json:"credentialsRef,omitempty"`
+	// Strategic merge patch applied to the pod template of the runner Jobs and CronJobs.
+	// The runner container is named "inference". Only the labels, the annotations, the nodeSelector, the tolerations,
+	// the affinity and the resources of the runner container can be set.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *v1.PodTemplateSpec `json:"podTemplate,omitempty"`
+	// Service account used by the runner pods, defaults to the runner service account of the controller
+	ServiceAccount *controllerapiv1.RunnerServiceAccountSpec `json:"serviceAccount,omitempty"`
+	// Environment variables of the runner container. Sensitive values should be read from Secrets with valueFrom
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Secrets and ConfigMaps exposed as environment variables in the runner container
+	EnvFrom []v1.EnvFromSource `json:"envFrom,omitempty"`
+	// Secrets mounted as volumes in the runner container
+	SecretMounts []controllerapiv1.SecretMount `json:"secretMounts,omitempty"`
+	// Collection of the logs of the runner container when the executions finish, not collected if not set
+	LogCollection *controllerapiv1.LogCollectionSpec `json:"logCollection,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+type InferenceConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InferenceConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InferenceConfig{}, &InferenceConfigList{})
+}
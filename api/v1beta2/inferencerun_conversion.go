@@ -0,0 +1,134 @@
+package v1beta2
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	controllerapiv1 "kserve-controller/api/v1"
+)
+
+// ConvertTo converts this InferenceRun to the hub version (v1).
+// Parameters with a value become v1 parameters, the ones with a source become v1 parametersFrom.
+func (src *InferenceRun) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*controllerapiv1.InferenceRun)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	spec := src.DeepCopy().Spec
+	dst.Spec = controllerapiv1.InferenceRunSpec{
+		ConfigRef:       spec.ConfigRef,
+		UpdatePolicy:    spec.Execution.UpdatePolicy,
+		HistoryLimit:    spec.Execution.HistoryLimit,
+		Sharding:        spec.Execution.Sharding,
+		StorageOverride: spec.Execution.StorageOverride,
+		Backfill:        spec.Backfill,
+		Matrix:          spec.Matrix,
+		CleanupPolicy:   spec.CleanupPolicy,
+	}
+	if spec.Execution.Timeout != nil {
+		dst.Spec.TimeoutSeconds = int(spec.Execution.Timeout.Duration / time.Second)
+	}
+
+	for _, parameter := range spec.Parameters {
+		if parameter.ValueFrom != nil {
+			dst.Spec.ParametersFrom = append(dst.Spec.ParametersFrom, controllerapiv1.ParameterSource{
+				Name:            parameter.Name,
+				ConfigMapKeyRef: parameter.ValueFrom.ConfigMapKeyRef,
+				SecretKeyRef:    parameter.ValueFrom.SecretKeyRef,
+			})
+			continue
+		}
+		if dst.Spec.Parameters == nil {
+			dst.Spec.Parameters = &map[string]string{}
+		}
+		(*dst.Spec.Parameters)[parameter.Name] = parameter.Value
+	}
+
+	if spec.Schedule != nil {
+		if spec.Schedule.Cron != "" {
+			dst.Spec.Schedule = &spec.Schedule.Cron
+		}
+		scheduling := controllerapiv1.SchedulingSpec{
+			TimeZone:                   spec.Schedule.TimeZone,
+			ConcurrencyPolicy:          spec.Schedule.ConcurrencyPolicy,
+			StartingDeadlineSeconds:    spec.Schedule.StartingDeadlineSeconds,
+			Suspend:                    spec.Schedule.Suspend,
+			SuccessfulJobsHistoryLimit: spec.Schedule.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     spec.Schedule.FailedJobsHistoryLimit,
+		}
+		if scheduling != (controllerapiv1.SchedulingSpec{}) {
+			dst.Spec.Scheduling = &scheduling
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version.
+// An empty, non nil, v1 parameters map has no equivalent and is converted to no parameters.
+func (dst *InferenceRun) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*controllerapiv1.InferenceRun)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	spec := src.DeepCopy().Spec
+	dst.Spec = InferenceRunSpec{
+		ConfigRef: spec.ConfigRef,
+		Execution: ExecutionSpec{
+			UpdatePolicy:    spec.UpdatePolicy,
+			HistoryLimit:    spec.HistoryLimit,
+			Sharding:        spec.Sharding,
+			StorageOverride: spec.StorageOverride,
+		},
+		Backfill:      spec.Backfill,
+		Matrix:        spec.Matrix,
+		CleanupPolicy: spec.CleanupPolicy,
+	}
+	if spec.TimeoutSeconds != 0 {
+		dst.Spec.Execution.Timeout = &metav1.Duration{Duration: time.Duration(spec.TimeoutSeconds) * time.Second}
+	}
+
+	// Map values are sorted by name, so that the conversion is deterministic
+	if spec.Parameters != nil {
+		names := make([]string, 0, len(*spec.Parameters))
+		for name := range *spec.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dst.Spec.Parameters = append(dst.Spec.Parameters, Parameter{
+				Name:  name,
+				Value: (*spec.Parameters)[name],
+			})
+		}
+	}
+	for _, source := range spec.ParametersFrom {
+		dst.Spec.Parameters = append(dst.Spec.Parameters, Parameter{
+			Name: source.Name,
+			ValueFrom: &ParameterValueSource{
+				ConfigMapKeyRef: source.ConfigMapKeyRef,
+				SecretKeyRef:    source.SecretKeyRef,
+			},
+		})
+	}
+
+	if spec.Schedule != nil || spec.Scheduling != nil {
+		dst.Spec.Schedule = &ScheduleSpec{}
+		if spec.Schedule != nil {
+			dst.Spec.Schedule.Cron = *spec.Schedule
+		}
+		if spec.Scheduling != nil {
+			dst.Spec.Schedule.TimeZone = spec.Scheduling.TimeZone
+			dst.Spec.Schedule.ConcurrencyPolicy = spec.Scheduling.ConcurrencyPolicy
+			dst.Spec.Schedule.StartingDeadlineSeconds = spec.Scheduling.StartingDeadlineSeconds
+			dst.Spec.Schedule.Suspend = spec.Scheduling.Suspend
+			dst.Spec.Schedule.SuccessfulJobsHistoryLimit = spec.Scheduling.SuccessfulJobsHistoryLimit
+			dst.Spec.Schedule.FailedJobsHistoryLimit = spec.Scheduling.FailedJobsHistoryLimit
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// +kubebuilder:object:generate=true
+package v1beta2
+
+import (
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	controllerapiv1 "kserve-controller/api/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+
+// InferenceRun is served only when the conversion webhook is enabled: the Helm chart serves the version and
+// configures the conversion of the CRD when the webhooks are enabled
+type InferenceRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferenceRunSpec                   `json:"spec,omitempty"`
+	Status controllerapiv1.InferenceRunStatus `json:"status,omitempty"`
+}
+
+type InferenceRunSpec struct {
+	ConfigRef *finopsdatatypes.ObjectRef `json:"configRef"`
+	// Parameters passed to the runner in the contract
+	// +listType=map
+	// +listMapKey=name
+	Parameters []Parameter `json:"parameters,omitempty"`
+	// How the Jobs of the InferenceRun are run
+	Execution ExecutionSpec `json:"execution,omitempty"`
+	// Runs the InferenceRun as a CronJob when cron is set
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+	// Runs the inference once for each interval of a past time range
+	Backfill *controllerapiv1.BackfillSpec `json:"backfill,omitempty"`
+	// Runs the inference once for each combination of parameter values, not supported with schedule and backfill
+	Matrix *controllerapiv1.MatrixSpec `json:"matrix,omitempty"`
+	// Overrides the fields of the cleanup policy of the InferenceConfig
+	CleanupPolicy *controllerapiv1.CleanupPolicy `json:"cleanupPolicy,omitempty"`
+}
+
+// Parameter is a parameter of the contract, with either a value or a source
+type Parameter struct {
+	Name string `json:"name"`
+	// Value of the parameter, it can be a template, e.g. {{ .ScheduledTime | addDays -1 | date "2006-01-02" }}
+	Value string `json:"value,omitempty"`
+	// Source of the value of the parameter
+	ValueFrom *ParameterValueSource `json:"valueFrom,omitempty"`
+}
+
+// ParameterValueSource sets a parameter from a key of a ConfigMap or of a Secret.
+// ConfigMap values are copied in the contract, Secret values are only exposed to the runner as environment variables.
+type ParameterValueSource struct {
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *v1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+type ExecutionSpec struct {
+	// Maximum duration of each Job, e.g. 30m. It is rounded down to seconds
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// How changes to the InferenceRun and to the InferenceConfig are applied to the existing Job or CronJob (default: InPlace)
+	// +kubebuilder:validation:Enum=InPlace;Recreate;Never
+	UpdatePolicy controllerapiv1.UpdatePolicy `json:"updatePolicy,omitempty"`
+	// Number of executions kept in status.history (default: 10)
+	// +kubebuilder:validation:Minimum=0
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+	// Runs the Jobs as Indexed Jobs, where each pod processes a shard of the input rows
+	Sharding *controllerapiv1.ShardingSpec `json:"sharding,omitempty"`
+	// Storage used instead of the storage of the InferenceConfig, e.g. to chain the steps of a pipeline
+	StorageOverride *controllerapiv1.StorageOverride `json:"storageOverride,omitempty"`
+}
+
+// ScheduleSpec mirrors the scheduling fields of the CronJob spec. Unset fields use the Kubernetes defaults.
+type ScheduleSpec struct {
+	// Cron expression of the CronJob, e.g. 0 2 * * *. The InferenceRun is scheduled only when it is set
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="(@(annually|yearly|monthly|weekly|daily|midnight|hourly))|((((\\d+,)+\\d+|(\\d+(\\/|-)\\d+)|\\d+|\\*) ?){5,7})"
+	Cron string `json:"cron,omitempty"`
+	// Time zone name for the schedule, e.g. Europe/Rome (default: time zone of the kube-controller-manager)
+	TimeZone *string `json:"timeZone,omitempty"`
+	// How to treat concurrent executions (default: Allow)
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy v1batch.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Deadline in seconds for starting the job if it misses its scheduled time
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Suspends subsequent executions, it does not apply to already started executions (default: false)
+	Suspend *bool `json:"suspend,omitempty"`
+	// Number of successful finished jobs to retain (default: 3)
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// Number of failed finished jobs to retain (default: 1)
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+type InferenceRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InferenceRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InferenceRun{}, &InferenceRunList{})
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+
+	v1batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	BACKFILL_LABEL       string = "ai.krateo.io/backfill"
+	BACKFILL_ID_LABEL    string = "ai.krateo.io/backfill-id"
+	BACKFILL_INDEX_LABEL string = "ai.krateo.io/backfill-index"
+	BACKFILL_MAX_WINDOWS int    = 1000
+)
+
+type backfillWindow struct {
+	Start metav1.Time
+	End   metav1.Time
+}
+
+// getBackfillWindows splits the backfill range in windows of the backfill interval, the last one is truncated at the end
+func getBackfillWindows(backfill *controllerapi.BackfillSpec) ([]backfillWindow, error) {
+	if backfill.Interval.Duration <= 0 {
+		return nil, fmt.Errorf("backfill interval must be positive")
+	}
+	if !backfill.Start.Before(&backfill.End) {
+		return nil, fmt.Errorf("backfill start must be before end")
+	}
+	windows := []backfillWindow{}
+	for start := backfill.Start.Time; start.Before(backfill.End.Time); start = start.Add(backfill.Interval.Duration) {
+		if len(windows) == BACKFILL_MAX_WINDOWS {
+			return nil, fmt.Errorf("backfill exceeds the maximum of %d windows", BACKFILL_MAX_WINDOWS)
+		}
+		end := start.Add(backfill.Interval.Duration)
+		if end.After(backfill.End.Time) {
+			end = backfill.End.Time
+		}
+		windows = append(windows, backfillWindow{Start: metav1.NewTime(start), End: metav1.NewTime(end)})
+	}
+	return windows, nil
+}
+
+// getBackfillId returns a hash of the range and interval of the backfill, to tell apart the Jobs of different backfills
+func getBackfillId(backfill *controllerapi.BackfillSpec) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s",
+		backfill.Start.UTC().Format(time.RFC3339), backfill.End.UTC().Format(time.RFC3339), backfill.Interval.Duration)))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func getBackfillJobName(jobName string, backfillId string, index int) string {
+	suffix := fmt.Sprintf("-%s-%d", backfillId, index)
+	if len(jobName) > 63-len(suffix) {
+		jobName = jobName[:63-len(suffix)]
+	}
+	return jobName + suffix
+}
+
+// listBackfillJobs returns the Jobs of the current backfill of the InferenceRun, by window index
+func listBackfillJobs(ctx context.Context, clientset kubernetes.Interface, jobName string, iRun *controllerapi.InferenceRun) (map[int]v1batch.Job, error) {
+	jobList, err := clientset.BatchV1().Jobs(iRun.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%s", BACKFILL_LABEL, jobName, BACKFILL_ID_LABEL, getBackfillId(iRun.Spec.Backfill)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list backfill jobs: %w", err)
+	}
+	jobs := map[int]v1batch.Job{}
+	for _, job := range jobList.Items {
+		index, err := strconv.Atoi(job.Labels[BACKFILL_INDEX_LABEL])
+		if err != nil {
+			continue
+		}
+		jobs[index] = job
+	}
+	return jobs, nil
+}
+
+// observeBackfill updates the backfill status of the InferenceRun and returns true if Jobs must be created for the
+// remaining windows
+func observeBackfill(ctx context.Context, jobName string, iRun *controllerapi.InferenceRun) (bool, error) {
+	if iRun.Spec.Backfill == nil {
+		iRun.Status.Backfill = nil
+		return false, nil
+	}
+
+	windows, err := getBackfillWindows(iRun.Spec.Backfill)
+	if err != nil {
+		return false, err
+	}
+
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return false, fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, err
+	}
+	jobs, err := listBackfillJobs(ctx, clientset, jobName, iRun)
+	if err != nil {
+		return false, err
+	}
+
+	status := &controllerapi.BackfillStatus{
+		Id:      getBackfillId(iRun.Spec.Backfill),
+		Windows: int32(len(windows)),
+	}
+	if iRun.Status.Backfill != nil && iRun.Status.Backfill.Id == status.Id {
+		status.CompletionTime = iRun.Status.Backfill.CompletionTime
+	}
+	status.Active, status.Succeeded, status.Failed = countJobs(jobs)
+	if status.Succeeded+status.Failed == status.Windows && status.CompletionTime == nil {
+		status.CompletionTime = &metav1.Time{Time: time.Now()}
+	}
+	iRun.Status.Backfill = status
+
+	return len(jobs) < len(windows) && status.Active < iRun.Spec.Backfill.GetMaxParallelism(), nil
+}
+
+// runBackfill creates the Jobs of the first windows without a Job, up to the max parallelism of the backfill
+func runBackfill(ctx context.Context, jobName string, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig) ([]string, error) {
+	windows, err := getBackfillWindows(iRun.Spec.Backfill)
+	if err != nil {
+		return nil, err
+	}
+
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return nil, fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := listBackfillJobs(ctx, clientset, jobName, iRun)
+	if err != nil {
+		return nil, err
+	}
+
+	active, _, _ := countJobs(jobs)
+
+	created := []string{}
+	backfillId := getBackfillId(iRun.Spec.Backfill)
+	for index, window := range windows {
+		if active >= iRun.Spec.Backfill.GetMaxParallelism() {
+			break
+		}
+		if _, ok := jobs[index]; ok {
+			continue
+		}
+		backfillJobName := getBackfillJobName(jobName, backfillId, index)
+		err := createBackfillJob(ctx, clientset, backfillJobName, jobName, backfillId, index, window, iRun, iConf)
+		if err != nil {
+			return created, fmt.Errorf("could not create backfill job %s: %w", backfillJobName, err)
+		}
+		created = append(created, backfillJobName)
+		active++
+	}
+	return created, nil
+}
+
+func createBackfillJob(ctx context.Context, clientset kubernetes.Interface, backfillJobName string, jobName string, backfillId string, index int, window backfillWindow, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig) error {
+	contract := job.ContractSpec{}
+	err := json.Unmarshal(iRun.Status.Contract, &contract)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal contract: %w", err)
+	}
+	contract.JobName = backfillJobName
+	contract.ExecutionTime = window.End.DeepCopy()
+	contract.WindowStart = window.Start.DeepCopy()
+	contract.WindowEnd = window.End.DeepCopy()
+	err = computeContractParameters(ctx, &contract, iRun, iConf, &window.End.Time)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{
+		RUN_LABEL:            jobName,
+		BACKFILL_LABEL:       jobName,
+		BACKFILL_ID_LABEL:    backfillId,
+		BACKFILL_INDEX_LABEL: strconv.Itoa(index),
+	}
+	return createChildJob(ctx, clientset, backfillJobName, labels, contract, iRun, iConf)
+}
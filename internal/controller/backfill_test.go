@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func TestGetBackfillWindows(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	backfill := func(end time.Time, interval time.Duration) *controllerapi.BackfillSpec {
+		return &controllerapi.BackfillSpec{
+			Start:    metav1.NewTime(start),
+			End:      metav1.NewTime(end),
+			Interval: metav1.Duration{Duration: interval},
+		}
+	}
+
+	tests := map[string]struct {
+		backfill *controllerapi.BackfillSpec
+		windows  int
+		lastEnd  time.Time
+		wantErr  bool
+	}{
+		"exact multiple of the interval": {
+			backfill: backfill(start.Add(72*time.Hour), 24*time.Hour),
+			windows:  3,
+			lastEnd:  start.Add(72 * time.Hour),
+		},
+		"last window truncated at the end": {
+			backfill: backfill(start.Add(60*time.Hour), 24*time.Hour),
+			windows:  3,
+			lastEnd:  start.Add(60 * time.Hour),
+		},
+		"interval longer than the range": {
+			backfill: backfill(start.Add(time.Hour), 24*time.Hour),
+			windows:  1,
+			lastEnd:  start.Add(time.Hour),
+		},
+		"maximum number of windows": {
+			backfill: backfill(start.Add(time.Duration(BACKFILL_MAX_WINDOWS)*time.Hour), time.Hour),
+			windows:  BACKFILL_MAX_WINDOWS,
+			lastEnd:  start.Add(time.Duration(BACKFILL_MAX_WINDOWS) * time.Hour),
+		},
+		"more than the maximum number of windows": {
+			backfill: backfill(start.Add(time.Duration(BACKFILL_MAX_WINDOWS)*time.Hour+time.Minute), time.Hour),
+			wantErr:  true,
+		},
+		"zero interval": {
+			backfill: backfill(start.Add(time.Hour), 0),
+			wantErr:  true,
+		},
+		"negative interval": {
+			backfill: backfill(start.Add(time.Hour), -time.Hour),
+			wantErr:  true,
+		},
+		"start equal to end": {
+			backfill: backfill(start, time.Hour),
+			wantErr:  true,
+		},
+		"start after end": {
+			backfill: backfill(start.Add(-time.Hour), time.Hour),
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			windows, err := getBackfillWindows(tc.backfill)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d windows", len(windows))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(windows) != tc.windows {
+				t.Fatalf("expected %d windows, got %d", tc.windows, len(windows))
+			}
+			if !windows[0].Start.Time.Equal(start) {
+				t.Errorf("expected the first window to start at %v, got %v", start, windows[0].Start)
+			}
+			for i := 1; i < len(windows); i++ {
+				if !windows[i].Start.Equal(&windows[i-1].End) {
+					t.Errorf("window %d starts at %v, the previous one ends at %v", i, windows[i].Start, windows[i-1].End)
+				}
+			}
+			if last := windows[len(windows)-1]; !last.End.Time.Equal(tc.lastEnd) {
+				t.Errorf("expected the last window to end at %v, got %v", tc.lastEnd, last.End)
+			}
+		})
+	}
+}
+
+func TestGetBackfillJobName(t *testing.T) {
+	tests := map[string]struct {
+		jobName string
+		want    string
+	}{
+		"short name": {
+			jobName: "inf-forecast-1234",
+			want:    "inf-forecast-1234-abcdef12-7",
+		},
+		"name truncated to 63 characters": {
+			jobName: "inf-" + strings.Repeat("a", 70),
+			want:    "inf-" + strings.Repeat("a", 48) + "-abcdef12-7",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := getBackfillJobName(tc.jobName, "abcdef12", 7)
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+			if len(got) > 63 {
+				t.Errorf("job name %s is longer than 63 characters", got)
+			}
+		})
+	}
+}
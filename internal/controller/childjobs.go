@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+
+	v1batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// createChildJob creates a Job of the InferenceRun besides its main Job or CronJob, as for backfill windows and matrix
+// combinations. Child Jobs are kept after they finish, so that each of them is run only once, and are deleted with the
+// InferenceRun together with their contract ConfigMap.
+func createChildJob(ctx context.Context, clientset kubernetes.Interface, childJobName string, labels map[string]string, contract job.ContractSpec, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig) error {
+	contract.JobName = childJobName
+	contractJson, err := json.Marshal(contract)
+	if err != nil {
+		return fmt.Errorf("could not marshal contract: %w", err)
+	}
+
+	// The ConfigMap is created first, so that it is there when the pod starts
+	err = createOrUpdateConfigMap(ctx, childJobName, iRun.Namespace, contractJson, iRun)
+	if err != nil {
+		return fmt.Errorf("could not create configmap: %w", err)
+	}
+
+	jobSpec, specHash, err := getDesiredJobSpec(childJobName, iRun, iConf, contractJson)
+	if err != nil {
+		return err
+	}
+	jobSpec.TTLSecondsAfterFinished = nil
+
+	childJob := &v1batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   childJobName,
+			Labels: labels,
+			Annotations: map[string]string{
+				SPEC_HASH_ANNOTATION: specHash,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(iRun, controllerapi.GroupVersion.WithKind("InferenceRun")),
+			},
+		},
+		Spec: jobSpec,
+	}
+	_, err = clientset.BatchV1().Jobs(iRun.Namespace).Create(ctx, childJob, metav1.CreateOptions{})
+	return err
+}
+
+// countJobs returns the number of active, succeeded and failed Jobs
+func countJobs[K comparable](jobs map[K]v1batch.Job) (int32, int32, int32) {
+	active, succeeded, failed := int32(0), int32(0), int32(0)
+	for key := range jobs {
+		job := jobs[key]
+		switch computeJobStatus(&job) {
+		case JobStatusSucceeded:
+			succeeded++
+		case JobStatusFailed:
+			failed++
+		default:
+			active++
+		}
+	}
+	return active, succeeded, failed
+}
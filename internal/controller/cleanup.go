@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	controllerapi "kserve-controller/api/v1"
+	clientHelper "kserve-controller/internal/helpers/kube/client"
+
+	v1batch "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// cleanupExecutions deletes the pods and the contract ConfigMaps of the finished executions in the history of the
+// InferenceRun whose TTL has expired. The ConfigMap of a CronJob is named after the CronJob, not after its Jobs,
+// so it is never deleted here.
+func cleanupExecutions(ctx context.Context, iRun *controllerapi.InferenceRun, policy controllerapi.CleanupPolicy) error {
+	if policy.PodTTLSecondsAfterFinished == nil && policy.ConfigMapTTLSecondsAfterFinished == nil {
+		return nil
+	}
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range iRun.Status.History {
+		if !recordFinished(record) {
+			continue
+		}
+		if ttlExpired(record.CompletionTime, policy.PodTTLSecondsAfterFinished) {
+			err := clientset.CoreV1().Pods(iRun.Namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", v1batch.JobNameLabel, record.JobName),
+			})
+			if err != nil {
+				return fmt.Errorf("could not delete pods of job %s: %w", record.JobName, err)
+			}
+		}
+		if ttlExpired(record.CompletionTime, policy.ConfigMapTTLSecondsAfterFinished) {
+			err := clientset.CoreV1().ConfigMaps(iRun.Namespace).Delete(ctx, record.JobName, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("could not delete configmap of job %s: %w", record.JobName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// cleanupRun deletes the InferenceRun without schedule when its TTL has expired or when at least keepLastRuns
+// finished InferenceRuns referencing the same InferenceConfig in its namespace finished after it.
+// It returns the reason of the deletion, empty if the InferenceRun has not been deleted.
+func cleanupRun(ctx context.Context, iRun *controllerapi.InferenceRun, policy controllerapi.CleanupPolicy, dynClient *dynamic.DynamicClient) (string, error) {
+	if policy.RunTTLSecondsAfterFinished == nil && policy.KeepLastRuns == nil {
+		return "", nil
+	}
+	completionTime := runCompletionTime(iRun)
+	if completionTime == nil {
+		return "", nil
+	}
+
+	reason := ""
+	if ttlExpired(completionTime, policy.RunTTLSecondsAfterFinished) {
+		reason = fmt.Sprintf("finished more than %d seconds ago", *policy.RunTTLSecondsAfterFinished)
+	} else if policy.KeepLastRuns != nil {
+		newer, err := countNewerRuns(ctx, iRun, completionTime, dynClient)
+		if err != nil {
+			return "", err
+		}
+		if newer >= int(*policy.KeepLastRuns) {
+			reason = fmt.Sprintf("%d newer InferenceRuns of InferenceConfig %s finished", newer, iRun.Spec.ConfigRef.Name)
+		}
+	}
+	if reason == "" {
+		return "", nil
+	}
+	if err := deleteRun(ctx, iRun); err != nil {
+		return "", err
+	}
+	return reason, nil
+}
+
+// countNewerRuns returns the number of finished InferenceRuns without schedule in the namespace of the InferenceRun
+// referencing the same InferenceConfig and finished after completionTime
+func countNewerRuns(ctx context.Context, iRun *controllerapi.InferenceRun, completionTime *metav1.Time, dynClient *dynamic.DynamicClient) (int, error) {
+	list, err := clientHelper.ListObj(ctx, iRun.Namespace, "ai.krateo.io/v1", "inferenceruns", dynClient)
+	if err != nil {
+		return 0, err
+	}
+	newer := 0
+	for _, item := range list.Items {
+		other := &controllerapi.InferenceRun{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, other); err != nil {
+			return 0, fmt.Errorf("unable to convert InferenceRun from unstructured: %w", err)
+		}
+		if other.UID == iRun.UID || other.DeletionTimestamp != nil || other.Spec.ConfigRef == nil || configRefKey(other) != configRefKey(iRun) {
+			continue
+		}
+		otherCompletionTime := runCompletionTime(other)
+		if otherCompletionTime == nil {
+			continue
+		}
+		// Runs finished at the same time are ordered by name, so that only one of them is kept
+		if completionTime.Before(otherCompletionTime) || (completionTime.Equal(otherCompletionTime) && iRun.Name < other.Name) {
+			newer++
+		}
+	}
+	return newer, nil
+}
+
+// runCompletionTime returns when the InferenceRun without schedule finished its Job, or all its matrix Jobs, and its
+// backfill Jobs. It returns nil for scheduled InferenceRuns and for InferenceRuns not finished yet.
+func runCompletionTime(iRun *controllerapi.InferenceRun) *metav1.Time {
+	if iRun.Spec.Schedule != nil {
+		return nil
+	}
+	var completionTime *metav1.Time
+	if iRun.Spec.Matrix != nil {
+		if iRun.Status.Matrix == nil || iRun.Status.Matrix.CompletionTime == nil {
+			return nil
+		}
+		completionTime = iRun.Status.Matrix.CompletionTime
+	} else {
+		if !runFinished(iRun) {
+			return nil
+		}
+		completionTime = iRun.Status.CompletionTime
+	}
+	if iRun.Spec.Backfill != nil {
+		if iRun.Status.Backfill == nil || iRun.Status.Backfill.CompletionTime == nil {
+			return nil
+		}
+		if completionTime.Before(iRun.Status.Backfill.CompletionTime) {
+			completionTime = iRun.Status.Backfill.CompletionTime
+		}
+	}
+	return completionTime
+}
+
+// runFinished returns true if the Job of the InferenceRun without schedule has finished, even if its record has left
+// the history
+func runFinished(iRun *controllerapi.InferenceRun) bool {
+	return iRun.Spec.Schedule == nil && iRun.Status.CompletionTime != nil &&
+		(iRun.Status.Result == controllerapi.ExecutionResultSucceeded || iRun.Status.Result == controllerapi.ExecutionResultFailed)
+}
+
+func recordFinished(record controllerapi.ExecutionRecord) bool {
+	return record.CompletionTime != nil &&
+		(record.Result == controllerapi.ExecutionResultSucceeded || record.Result == controllerapi.ExecutionResultFailed)
+}
+
+func ttlExpired(completionTime *metav1.Time, ttlSeconds *int32) bool {
+	if completionTime == nil || ttlSeconds == nil {
+		return false
+	}
+	return !time.Now().Before(completionTime.Add(time.Duration(*ttlSeconds) * time.Second))
+}
@@ -1,12 +1,20 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/krateoplatformops/provider-runtime/pkg/controller"
 	"github.com/krateoplatformops/provider-runtime/pkg/event"
@@ -14,13 +22,17 @@ import (
 	"github.com/krateoplatformops/provider-runtime/pkg/ratelimiter"
 	"github.com/krateoplatformops/provider-runtime/pkg/reconciler"
 	"github.com/krateoplatformops/provider-runtime/pkg/resource"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
 	prv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
 
 	controllerapi "kserve-controller/api/v1"
@@ -28,6 +40,8 @@ import (
 	"kserve-controller/internal/helpers/config"
 	"kserve-controller/internal/helpers/job"
 	clientHelper "kserve-controller/internal/helpers/kube/client"
+	"kserve-controller/internal/helpers/parameters"
+	"kserve-controller/internal/helpers/tracing"
 )
 
 type JobStatus string
@@ -39,7 +53,11 @@ const (
 	JobStatusFailed    JobStatus = "Failed"
 	JobStatusUnknown   JobStatus = "Unknown"
 
-	JOB_NAME_PREFIX string = "inf"
+	JOB_NAME_PREFIX      string = "inf"
+	SPEC_HASH_ANNOTATION string = "ai.krateo.io/spec-hash"
+	// Set to the name of the Job or CronJob of the InferenceRun on all the Jobs of the run, see listRunJobs
+	RUN_LABEL        string = "ai.krateo.io/run"
+	CONFIG_REF_INDEX string = "spec.configRef"
 )
 
 func Setup(mgr ctrl.Manager, o controller.Options, config config.Configuration) error {
@@ -62,13 +80,59 @@ func Setup(mgr ctrl.Manager, o controller.Options, config config.Configuration)
 		reconciler.WithLogger(log),
 		reconciler.WithRecorder(event.NewAPIRecorder(recorder)))
 
+	// Index the InferenceRuns by referenced InferenceConfig, to enqueue them when the InferenceConfig changes
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &controllerapi.InferenceRun{}, CONFIG_REF_INDEX, func(obj client.Object) []string {
+		iRun, ok := obj.(*controllerapi.InferenceRun)
+		if !ok || iRun.Spec.ConfigRef == nil {
+			return nil
+		}
+		return []string{configRefKey(iRun)}
+	})
+	if err != nil {
+		return fmt.Errorf("unable to index InferenceRuns by %s: %w", CONFIG_REF_INDEX, err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
 		For(&controllerapi.InferenceRun{}).
+		Watches(&controllerapi.InferenceConfig{},
+			handler.EnqueueRequestsFromMapFunc(enqueueRunsForConfig(mgr.GetClient(), log)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(ratelimiter.New(name, r, o.GlobalRateLimiter))
 }
 
+// configRefKey returns the namespace/name of the InferenceConfig referenced by the InferenceRun
+func configRefKey(iRun *controllerapi.InferenceRun) string {
+	namespace := iRun.Spec.ConfigRef.Namespace
+	if namespace == "" {
+		namespace = iRun.Namespace
+	}
+	return namespace + "/" + iRun.Spec.ConfigRef.Name
+}
+
+func enqueueRunsForConfig(kube client.Client, log logging.Logger) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		iRuns := &controllerapi.InferenceRunList{}
+		err := kube.List(ctx, iRuns, client.MatchingFields{CONFIG_REF_INDEX: obj.GetNamespace() + "/" + obj.GetName()})
+		if err != nil {
+			log.Warn(fmt.Sprintf("unable to list InferenceRuns referencing InferenceConfig %s: %v", obj.GetName(), err))
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(iRuns.Items))
+		for _, iRun := range iRuns.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: iRun.Namespace, Name: iRun.Name},
+			})
+		}
+		if len(requests) > 0 {
+			log.Info(fmt.Sprintf("InferenceConfig %s changed, enqueuing %d InferenceRuns", obj.GetName(), len(requests)))
+		}
+		return requests
+	}
+}
+
 type connector struct {
 	pollInterval time.Duration
 	log          logging.Logger
@@ -107,40 +171,99 @@ func (c *external) Disconnect(_ context.Context) error {
 	return nil // NOOP
 }
 
-func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler.ExternalObservation, error) {
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (observation reconciler.ExternalObservation, err error) {
 	iRun, ok := mg.(*controllerapi.InferenceRun)
 	if !ok {
 		return reconciler.ExternalObservation{}, fmt.Errorf("cannot cast to controllerapi.InferenceRun")
 	}
 
+	ctx, span := startSpan(ctx, "InferenceRun.Observe", iRun)
+	defer func() { endSpan(span, err) }()
+
 	log := e.log.WithValues("Reconcile", "Observe", "name", iRun.Name, "namespace", iRun.Namespace)
 
 	iConf, err := getIConf(ctx, iRun, e.dynClient)
 	if err != nil {
 		log.Warn(fmt.Sprintf("unable to retrieve InferenceConfig referenced in InferenceRun: %v", err))
+		e.rec.Eventf(iRun, v1.EventTypeWarning, ReasonConfigMissing, "Unable to retrieve the referenced InferenceConfig: %v", err)
 		return reconciler.ExternalObservation{
 			ResourceExists: false,
 		}, nil
 	}
 	log.Info(fmt.Sprintf("retrieved InferenceConfig %s", iConf.Name))
 
-	if *iConf.Spec.AutoDeletePolicy != controllerapi.AutoDeletePolicyNone && iRun.Spec.Schedule != nil {
-		log.Warn("AutoDeletePolicy is incompatible with schedule: AutoDeletePolicy will be ignored", "AutoDeletePolicy", string(*iConf.Spec.AutoDeletePolicy), "Schedule", *iRun.Spec.Schedule)
+	// The service account of the runners follows the changes of the InferenceConfig, before the storage override
+	// that only applies to this InferenceRun
+	if err := ensureRunnerRBAC(ctx, iConf); err != nil {
+		return reconciler.ExternalObservation{}, fmt.Errorf("unable to reconcile runner service account for InferenceConfig %s: %w", iConf.Name, err)
+	}
+
+	if override := iRun.Spec.StorageOverride; override != nil {
+		if len(override.Input) > 0 {
+			iConf.Spec.Storage.Input = override.Input
+		}
+		if len(override.Output) > 0 {
+			iConf.Spec.Storage.Output = override.Output
+		}
+	}
+
+	if _, _, err := iConf.GetStorageProvider(); err != nil {
+		observeContractError(iRun, CONTRACT_ERROR_STORAGE)
+		return reconciler.ExternalObservation{}, fmt.Errorf("invalid storage in InferenceConfig %s: %w", iConf.Name, err)
+	}
+
+	if iConf.GetAutoDeletePolicy() != controllerapi.AutoDeletePolicyNone && iRun.Spec.Schedule != nil {
+		log.Warn("AutoDeletePolicy is incompatible with schedule: AutoDeletePolicy will be ignored", "AutoDeletePolicy", string(iConf.GetAutoDeletePolicy()), "Schedule", *iRun.Spec.Schedule)
+		e.rec.Eventf(iRun, v1.EventTypeWarning, ReasonAutoDeletePolicyIgnored, "AutoDeletePolicy %s of InferenceConfig %s is ignored for scheduled runs", iConf.GetAutoDeletePolicy(), iConf.Name)
 	}
 
 	jobName := helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID))
 
 	contract := job.ContractSpec{
-		JobId:      string(iRun.UID),
-		JobName:    jobName,
-		KServe:     iConf.Spec.KServe,
-		Input:      iConf.Spec.Storage.Input,
-		Output:     iConf.Spec.Storage.Output,
-		Parameters: iRun.Spec.Parameters,
+		JobId:        string(iRun.UID),
+		JobName:      jobName,
+		RunName:      iRun.Name,
+		Namespace:    iRun.Namespace,
+		KServe:       iConf.Spec.KServe,
+		Input:        iConf.Spec.Storage.Input,
+		Output:       iConf.Spec.Storage.Output,
+		OutputFormat: iConf.Spec.Storage.OutputFormat,
+		Secrets:      job.ComputeContractSecrets(iConf),
+	}
+	if iRun.Spec.Sharding != nil {
+		contract.ShardCount = iRun.Spec.Sharding.Shards
+	}
+	if e.config.EnableTracing {
+		contract.TraceContext = tracing.TraceContext(iRun.UID)
+	}
+
+	// One-shot runs are rendered with their creation time, CronJobs are rendered by the runner at each execution
+	var scheduledTime *time.Time
+	if iRun.Spec.Schedule == nil {
+		scheduledTime = &iRun.CreationTimestamp.Time
+	}
+	paramsCtx, paramsSpan := tracer.Start(ctx, "computeContractParameters")
+	err = computeContractParameters(paramsCtx, &contract, iRun, iConf, scheduledTime)
+	endSpan(paramsSpan, err)
+	var paramErr *parameters.ValidationError
+	if errors.As(err, &paramErr) {
+		// No Job is run with invalid parameters, the errors are reported until the InferenceRun or the schema is fixed
+		observeContractError(iRun, CONTRACT_ERROR_PARAMETERS)
+		e.rec.Eventf(iRun, v1.EventTypeWarning, ReasonInvalidParameters, "Invalid parameters: %s", strings.Join(paramErr.Errors, "; "))
+		iRun.Status.ParameterErrors = paramErr.Errors
+		if err := updateStatus(ctx, iRun); err != nil {
+			log.Warn(fmt.Sprintf("unable to update InferenceRun status: %v", err))
+		}
+		return reconciler.ExternalObservation{}, err
+	} else if err != nil {
+		observeContractError(iRun, CONTRACT_ERROR_PARAMETERS)
+		return reconciler.ExternalObservation{}, fmt.Errorf("unable to compute parameters: %w", err)
 	}
+	iRun.Status.ParameterErrors = nil
 
 	contractJson, err := json.Marshal(contract)
 	if err != nil {
+		observeContractError(iRun, CONTRACT_ERROR_MARSHAL)
 		return reconciler.ExternalObservation{}, fmt.Errorf("unable to marshal contract to json: %w", err)
 	}
 
@@ -163,12 +286,103 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 	} else {
 		iRun.Status.JobStatus = nil
 	}
+	if len(iRun.Status.Contract) > 0 && !bytes.Equal(iRun.Status.Contract, contractJson) {
+		e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonContractUpdated, "Contract updated from InferenceConfig %s generation %d", iConf.Name, iConf.Generation)
+	}
 	iRun.Status.Contract = contractJson
+	iRun.Status.ConfigGeneration = iConf.Generation
+	err = updateHistory(ctx, jobName, iRun, iConf.Spec.LogCollection, e.rec)
+	if err != nil {
+		log.Warn(fmt.Sprintf("unable to update execution history: %v", err))
+	}
+	backfillPending, err := observeBackfill(ctx, jobName, iRun)
+	if err != nil {
+		log.Warn(fmt.Sprintf("unable to observe backfill: %v", err))
+	}
+	matrixPending, matrixErr := observeMatrix(ctx, jobName, iRun)
 	err = updateStatus(ctx, iRun)
 	if err != nil {
 		return reconciler.ExternalObservation{}, fmt.Errorf("unable to update InferenceRun status: %w", err)
 	}
 
+	cleanupPolicy := iRun.GetCleanupPolicy(iConf)
+	if err := cleanupExecutions(ctx, iRun, cleanupPolicy); err != nil {
+		log.Warn(fmt.Sprintf("unable to clean up finished executions: %v", err))
+	}
+	if reason, err := cleanupRun(ctx, iRun, cleanupPolicy, e.dynClient); err != nil {
+		log.Warn(fmt.Sprintf("unable to clean up InferenceRun: %v", err))
+	} else if reason != "" {
+		log.Info(fmt.Sprintf("deleted InferenceRun %s for cleanupPolicy: %s", iRun.Name, reason))
+		e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonCleanedUp, "Deleted for cleanupPolicy: %s", reason)
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: true,
+		}, nil
+	}
+
+	// Matrix runs have no main Job, only one Job for each combination
+	if iRun.Spec.Matrix != nil {
+		if matrixErr != nil {
+			return reconciler.ExternalObservation{}, fmt.Errorf("invalid matrix: %w", matrixErr)
+		}
+		status := iRun.Status.Matrix
+		if status.Active+status.Succeeded+status.Failed == 0 {
+			log.Info(fmt.Sprintf("%s does not have matrix jobs yet", iRun.Name))
+			return reconciler.ExternalObservation{
+				ResourceExists: false,
+			}, nil
+		}
+		if matrixPending {
+			log.Info(fmt.Sprintf("InferenceRun %s has matrix combinations to run", iRun.Name))
+			return reconciler.ExternalObservation{
+				ResourceExists:   true,
+				ResourceUpToDate: false,
+			}, nil
+		}
+		iRun.SetConditions(prv1.Available())
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: true,
+		}, nil
+	}
+
+	_, specHash, err := getDesiredJobSpec(jobName, iRun, iConf, contractJson)
+	if err != nil {
+		return reconciler.ExternalObservation{}, fmt.Errorf("unable to compute job spec: %w", err)
+	}
+	updatePolicy := iRun.Spec.GetUpdatePolicy()
+
+	if iRun.Spec.Schedule != nil {
+		cronJob, err := getCronJob(jobName, iRun)
+		if err == nil && cronJob.Annotations[SPEC_HASH_ANNOTATION] == "" {
+			if err := annotateSpecHash(ctx, "cronjobs", jobName, specHash, iRun); err != nil {
+				log.Warn(fmt.Sprintf("unable to annotate CronJob %s with its spec hash: %v", jobName, err))
+			}
+		}
+		if err == nil && !cronJobUpToDate(cronJob, specHash, iRun) {
+			log.Info(fmt.Sprintf("CronJob %s is not up to date", jobName))
+			return reconciler.ExternalObservation{
+				ResourceExists:   true,
+				ResourceUpToDate: false,
+			}, nil
+		}
+		if err == nil && triggerPending(iRun) {
+			log.Info(fmt.Sprintf("InferenceRun %s has been triggered at %s", iRun.Name, iRun.Annotations[TRIGGER_AT_ANNOTATION]))
+			return reconciler.ExternalObservation{
+				ResourceExists:   true,
+				ResourceUpToDate: false,
+			}, nil
+		}
+	}
+
+	if backfillPending && (job != nil || cronJobExists) {
+		log.Info(fmt.Sprintf("InferenceRun %s has backfill windows to run", iRun.Name))
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: false,
+		}, nil
+	}
+
 	if cronJobExists {
 		log.Warn("CronJob exists, assuming everything is fine")
 		return reconciler.ExternalObservation{
@@ -177,12 +391,41 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 		}, nil
 	}
 
-	err = createOrUpdateConfigMap(ctx, jobName, iRun.Namespace, iRun.Status.Contract, iRun)
-	if err != nil {
-		return reconciler.ExternalObservation{}, fmt.Errorf("unable to create configmap for job %s: %w", jobName, err)
+	// Jobs deleted by their TTL after succeeding are not run again
+	if job == nil && runFinished(iRun) && iRun.Status.Result == controllerapi.ExecutionResultSucceeded {
+		log.Info(fmt.Sprintf("job %s succeeded and has been deleted", jobName))
+		iRun.SetConditions(prv1.Available())
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: true,
+		}, nil
+	}
+
+	// The ConfigMap of a finished Job is not re-created, so that it can be deleted by the cleanupPolicy.
+	// Failed Jobs are deleted before being retried, so their ConfigMap is re-created first
+	if job == nil || !jobFinished(job) {
+		err = createOrUpdateConfigMap(ctx, jobName, iRun.Namespace, iRun.Status.Contract, iRun)
+		if err != nil {
+			return reconciler.ExternalObservation{}, fmt.Errorf("unable to create configmap for job %s: %w", jobName, err)
+		}
+
+		log.Info(fmt.Sprintf("created configmap for job %s with contract %s", jobName, string(iRun.Status.Contract)))
+	}
+
+	if job != nil && job.Annotations[SPEC_HASH_ANNOTATION] == "" {
+		if err := annotateSpecHash(ctx, "jobs", jobName, specHash, iRun); err != nil {
+			log.Warn(fmt.Sprintf("unable to annotate job %s with its spec hash: %v", jobName, err))
+		}
 	}
 
-	log.Info(fmt.Sprintf("created configmap for job %s with contract %s", jobName, string(iRun.Status.Contract)))
+	// Finished one-shot Jobs are left alone, recreating them would run the inference again
+	if job != nil && updatePolicy == controllerapi.UpdatePolicyRecreate && !jobFinished(job) && specHashOutdated(job.Annotations, specHash) {
+		log.Info(fmt.Sprintf("job %s is not up to date and will be recreated", jobName))
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: false,
+		}, nil
+	}
 
 	if iRun.Status.JobStatus == nil {
 		log.Info(fmt.Sprintf("%s does not have a job yet", iRun.Name))
@@ -219,6 +462,7 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 					if err != nil {
 						return reconciler.ExternalObservation{}, fmt.Errorf("unable to delete InferenceRun %s: %w", iRun.Name, err)
 					}
+					e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonAutoDeleted, "Deleted for AutoDeletePolicy %s of InferenceConfig %s", iConf.GetAutoDeletePolicy(), iConf.Name)
 				}
 			}
 			// These are reported explicitly, but commented since they are the same and covered outside the if
@@ -249,12 +493,15 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (reconciler
 	}, nil
 }
 
-func (e *external) Create(ctx context.Context, mg resource.Managed) error {
+func (e *external) Create(ctx context.Context, mg resource.Managed) (err error) {
 	iRun, ok := mg.(*controllerapi.InferenceRun)
 	if !ok {
 		return fmt.Errorf("cannot cast to controllerapi.InferenceRun")
 	}
 
+	ctx, span := startSpan(ctx, "InferenceRun.Create", iRun)
+	defer func() { endSpan(span, err) }()
+
 	log := e.log.WithValues("Reconcile", "Create", "name", iRun.Name, "namespace", iRun.Namespace)
 
 	iConf, err := getIConf(ctx, iRun, e.dynClient)
@@ -268,12 +515,31 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) error {
 
 	jobName := helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID))
 
+	if iRun.Spec.Matrix != nil {
+		created, err := runMatrix(ctx, jobName, iRun, iConf)
+		if err != nil {
+			return fmt.Errorf("unable to create matrix jobs: %w", err)
+		}
+		log.Info(fmt.Sprintf("created matrix jobs %v for InferenceRun %s", created, iRun.Name))
+		if len(created) > 0 {
+			e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created matrix Jobs %s", strings.Join(created, ", "))
+		}
+		return nil
+	}
+
+	_, jobSpan := tracer.Start(ctx, "createJobOrCronJob", trace.WithAttributes(attribute.String("job.name", jobName)))
 	err = createJobOrCronJob(jobName, iRun, iConf)
+	endSpan(jobSpan, err)
 	if err != nil {
 		return fmt.Errorf("unable to create job: %w", err)
 	}
 
 	log.Info(fmt.Sprintf("created job %s for InferenceRun %s", jobName, iRun.Name))
+	if iRun.Spec.Schedule != nil {
+		e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created CronJob %s with schedule %s", jobName, *iRun.Spec.Schedule)
+	} else {
+		e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created Job %s", jobName)
+	}
 
 	job, err, _ := getJob(jobName, iRun)
 	if err != nil {
@@ -299,12 +565,15 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) error {
 	return nil
 }
 
-func (e *external) Update(ctx context.Context, mg resource.Managed) error {
+func (e *external) Update(ctx context.Context, mg resource.Managed) (err error) {
 	iRun, ok := mg.(*controllerapi.InferenceRun)
 	if !ok {
 		return fmt.Errorf("cannot cast to controllerapi.InferenceRun")
 	}
 
+	ctx, span := startSpan(ctx, "InferenceRun.Update", iRun)
+	defer func() { endSpan(span, err) }()
+
 	log := e.log.WithValues("Reconcile", "Update", "name", iRun.Name, "namespace", iRun.Namespace)
 
 	iConf, err := getIConf(ctx, iRun, e.dynClient)
@@ -313,7 +582,62 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) error {
 	}
 	log.Info(fmt.Sprintf("retrieved InferenceConfig %s for %s", iConf.Name, iRun.Name))
 
-	job, err, _ := getJob(helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID)), iRun)
+	jobName := helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID))
+
+	if iRun.Spec.Matrix != nil {
+		created, err := runMatrix(ctx, jobName, iRun, iConf)
+		if err != nil {
+			return fmt.Errorf("unable to create matrix jobs: %w", err)
+		}
+		log.Info(fmt.Sprintf("created matrix jobs %v for InferenceRun %s", created, iRun.Name))
+		if len(created) > 0 {
+			e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created matrix Jobs %s", strings.Join(created, ", "))
+		}
+		return nil
+	}
+
+	jobSpec, specHash, err := getDesiredJobSpec(jobName, iRun, iConf, iRun.Status.Contract)
+	if err != nil {
+		return fmt.Errorf("unable to compute job spec: %w", err)
+	}
+
+	if iRun.Spec.Schedule != nil {
+		cronJob, err := getCronJob(jobName, iRun)
+		if err == nil && !cronJobUpToDate(cronJob, specHash, iRun) {
+			log.Info(fmt.Sprintf("updating CronJob %s", jobName))
+			if err := updateCronJob(ctx, cronJob, jobSpec, specHash, iRun); err != nil {
+				return fmt.Errorf("unable to update CronJob %s: %w", jobName, err)
+			}
+			if err := createOrUpdateConfigMap(ctx, jobName, iRun.Namespace, iRun.Status.Contract, iRun); err != nil {
+				return fmt.Errorf("unable to update configmap for CronJob %s: %w", jobName, err)
+			}
+			return nil
+		}
+		if err == nil && triggerPending(iRun) {
+			triggerJobName, err := triggerJob(ctx, cronJob, iRun)
+			if err != nil {
+				return fmt.Errorf("unable to trigger CronJob %s: %w", jobName, err)
+			}
+			log.Info(fmt.Sprintf("created job %s for trigger %s", triggerJobName, iRun.Annotations[TRIGGER_AT_ANNOTATION]))
+			e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created Job %s for trigger %s", triggerJobName, iRun.Annotations[TRIGGER_AT_ANNOTATION])
+			iRun.Status.LastTriggerAt = iRun.Annotations[TRIGGER_AT_ANNOTATION]
+			return updateStatus(ctx, iRun)
+		}
+	}
+
+	if backfillPending, err := observeBackfill(ctx, jobName, iRun); err == nil && backfillPending {
+		created, err := runBackfill(ctx, jobName, iRun, iConf)
+		log.Info(fmt.Sprintf("created backfill jobs %v", created))
+		if len(created) > 0 {
+			e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobCreated, "Created backfill Jobs %s", strings.Join(created, ", "))
+		}
+		if err != nil {
+			return fmt.Errorf("unable to run backfill: %w", err)
+		}
+		return nil
+	}
+
+	job, err, _ := getJob(jobName, iRun)
 	if err != nil {
 		log.Warn(fmt.Sprintf("unable to retrieve job: %v", err))
 	}
@@ -334,6 +658,16 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) error {
 		return fmt.Errorf("unable to update InferenceRun status: %w", err)
 	}
 
+	if job != nil && iRun.Spec.Schedule == nil && iRun.Spec.GetUpdatePolicy() == controllerapi.UpdatePolicyRecreate && !jobFinished(job) && specHashOutdated(job.Annotations, specHash) {
+		// The job is re-created with the new spec by the next reconcile
+		log.Info(fmt.Sprintf("deleting job %s to recreate it with the new spec", jobName))
+		err := deleteJob(iRun, jobName, true)
+		if err != nil {
+			return fmt.Errorf("unable to delete job for InferenceRun %s: %w", iRun.Name, err)
+		}
+		return nil
+	}
+
 	if iRun.Status.JobStatus != nil {
 		log.Info(fmt.Sprintf("checking autoDeletePolicy for InferenceRun %s", iRun.Name))
 		if autoDeletePolicy(iConf, computeJobStatus(job)) && iRun.Spec.Schedule != nil {
@@ -342,23 +676,31 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) error {
 			if err != nil {
 				return fmt.Errorf("unable to delete job for InferenceRun %s: %w", iRun.Name, err)
 			}
+			e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonAutoDeleted, "Deleted for AutoDeletePolicy %s of InferenceConfig %s", iConf.GetAutoDeletePolicy(), iConf.Name)
 		} else {
 			// Re-create the job to restart it
-			err := deleteJob(iRun, helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID)), true)
+			err := deleteJob(iRun, jobName, true)
 			if err != nil {
 				return fmt.Errorf("unable to delete job for InferenceRun %s: %w", iRun.Name, err)
 			}
+			if job != nil && computeJobStatus(job) == JobStatusFailed {
+				observeRetry(iRun)
+				e.rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobRetried, "Job %s failed and is re-created", jobName)
+			}
 		}
 	}
 	return nil
 }
 
-func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+func (e *external) Delete(ctx context.Context, mg resource.Managed) (err error) {
 	iRun, ok := mg.(*controllerapi.InferenceRun)
 	if !ok {
 		return fmt.Errorf("cannot cast to controllerapi.InferenceRun")
 	}
 
+	ctx, span := startSpan(ctx, "InferenceRun.Delete", iRun)
+	defer func() { endSpan(span, err) }()
+
 	log := e.log.WithValues("Reconcile", "Delete", "name", iRun.Name, "namespace", iRun.Namespace)
 
 	iConf, err := getIConf(ctx, iRun, e.dynClient)
@@ -368,6 +710,13 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 	log.Info(fmt.Sprintf("retrieved InferenceConfig %s", iConf.Name))
 
 	iRun.SetConditions(prv1.Deleting())
+	setActiveExecutions(iRun, 0)
+
+	if iRun.Spec.Matrix != nil {
+		// Matrix Jobs are owned by the InferenceRun and are garbage collected with it
+		log.Info(fmt.Sprintf("receive delete for %s, matrix jobs are deleted with it", iRun.Name))
+		return nil
+	}
 
 	jobName := helpers.ComputeJobName(JOB_NAME_PREFIX, iRun.Name, string(iRun.UID))
 
@@ -409,6 +758,18 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 }
 
 func computeJobStatus(job *v1batch.Job) JobStatus {
+	// Indexed Jobs can have both succeeded and failed pods, the conditions tell the final outcome
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != v1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case v1batch.JobFailed:
+			return JobStatusFailed
+		case v1batch.JobComplete:
+			return JobStatusSucceeded
+		}
+	}
 	if job.Status.Active == 0 && job.Status.Succeeded == 0 && job.Status.Failed == 0 {
 		return JobStatusPending
 	} else if job.Status.Active > 0 {
@@ -421,10 +782,26 @@ func computeJobStatus(job *v1batch.Job) JobStatus {
 	return JobStatusUnknown
 }
 
+func jobFinished(job *v1batch.Job) bool {
+	status := computeJobStatus(job)
+	return status == JobStatusSucceeded || status == JobStatusFailed
+}
+
 func getIConf(ctx context.Context, iRun *controllerapi.InferenceRun, dynClient *dynamic.DynamicClient) (*controllerapi.InferenceConfig, error) {
-	iConfUn, err := clientHelper.GetObj(ctx, iRun.Spec.ConfigRef, "ai.krateo.io/v1", "inferenceconfigs", dynClient)
+	if iRun.Spec.ConfigRef == nil {
+		return nil, fmt.Errorf("InferenceRun %s has no configRef", iRun.Name)
+	}
+	ref := iRun.Spec.ConfigRef.DeepCopy()
+	if ref.Namespace == "" {
+		ref.Namespace = iRun.Namespace
+	}
+	return getIConfByRef(ctx, ref, dynClient)
+}
+
+func getIConfByRef(ctx context.Context, ref *finopsdatatypes.ObjectRef, dynClient *dynamic.DynamicClient) (*controllerapi.InferenceConfig, error) {
+	iConfUn, err := clientHelper.GetObj(ctx, ref, "ai.krateo.io/v1", "inferenceconfigs", dynClient)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve InferenceConfig referenced in InferenceRun: %w", err)
+		return nil, fmt.Errorf("unable to retrieve InferenceConfig %s: %w", ref.Name, err)
 	}
 
 	iConf := &controllerapi.InferenceConfig{}
@@ -432,6 +809,8 @@ func getIConf(ctx context.Context, iRun *controllerapi.InferenceRun, dynClient *
 	if err != nil {
 		return nil, fmt.Errorf("unable to convert InferenceConfig from unstructured: %w", err)
 	}
+	// InferenceConfigs created without the defaulting webhook may miss the defaults
+	iConf.Default()
 	return iConf, nil
 }
 
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+// Reasons of the events recorded on the InferenceRuns
+const (
+	ReasonJobCreated              = "JobCreated"
+	ReasonJobStarted              = "JobStarted"
+	ReasonJobSucceeded            = "JobSucceeded"
+	ReasonJobFailed               = "JobFailed"
+	ReasonJobRetried              = "JobRetried"
+	ReasonAutoDeleted             = "AutoDeleted"
+	ReasonConfigMissing           = "ConfigMissing"
+	ReasonContractUpdated         = "ContractUpdated"
+	ReasonInvalidParameters       = "InvalidParameters"
+	ReasonAutoDeletePolicyIgnored = "AutoDeletePolicyIgnored"
+	ReasonCleanedUp               = "CleanedUp"
+)
+
+// recordExecutionEvents records the start and the completion of an execution when its record changes, like
+// observeExecution. Executions already recorded in the status do not emit events again after a restart.
+func recordExecutionEvents(rec record.EventRecorder, iRun *controllerapi.InferenceRun, previous *controllerapi.ExecutionRecord, record controllerapi.ExecutionRecord) {
+	if rec == nil {
+		return
+	}
+	if record.StartTime != nil && (previous == nil || previous.StartTime == nil) {
+		rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobStarted, "Job %s started", record.JobName)
+	}
+	switch record.Result {
+	case controllerapi.ExecutionResultSucceeded:
+		if record.ResultSummary != "" {
+			rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobSucceeded, "Job %s succeeded: %s", record.JobName, record.ResultSummary)
+		} else {
+			rec.Eventf(iRun, v1.EventTypeNormal, ReasonJobSucceeded, "Job %s succeeded", record.JobName)
+		}
+	case controllerapi.ExecutionResultFailed:
+		rec.Eventf(iRun, v1.EventTypeWarning, ReasonJobFailed, "Job %s failed: %s", record.JobName, record.FailureReason)
+	}
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	controllerapi "kserve-controller/api/v1"
+	jobHelper "kserve-controller/internal/helpers/job"
+
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	RESULT_SUMMARY_MAX_LENGTH int = 512
+	// Set on the finished Jobs once their execution has been recorded, so that Jobs whose record has left the history,
+	// e.g. matrix and backfill Jobs beyond the history limit, are not recorded again at each reconcile
+	OBSERVED_ANNOTATION string = "ai.krateo.io/observed"
+)
+
+// updateHistory records in the status of the InferenceRun the executions of its Job or of the Jobs spawned by its CronJob.
+// Records of Jobs that no longer exist are kept, up to the history limit of the InferenceRun.
+// The start and the completion of the executions are recorded as events of the InferenceRun, the logs of the finished
+// executions are collected according to logCollection.
+func updateHistory(ctx context.Context, jobName string, iRun *controllerapi.InferenceRun, logCollection *controllerapi.LogCollectionSpec, rec record.EventRecorder) error {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return recordHistory(ctx, clientset, jobName, iRun, logCollection, rec)
+}
+
+func recordHistory(ctx context.Context, clientset kubernetes.Interface, jobName string, iRun *controllerapi.InferenceRun, logCollection *controllerapi.LogCollectionSpec, rec record.EventRecorder) error {
+	jobs, err := listRunJobs(ctx, clientset, jobName, iRun)
+	if err != nil {
+		return err
+	}
+
+	records := map[string]controllerapi.ExecutionRecord{}
+	for _, record := range iRun.Status.History {
+		records[record.JobName] = record
+	}
+	var errs []error
+	for i := range jobs {
+		// Finished executions are not computed again, unless the Job has been recreated with the same name
+		if _, ok := jobs[i].Annotations[OBSERVED_ANNOTATION]; ok {
+			continue
+		}
+		previous, ok := records[jobs[i].Name]
+		if ok && previous.StartTime.Equal(jobs[i].Status.StartTime) &&
+			(previous.Result == controllerapi.ExecutionResultSucceeded || previous.Result == controllerapi.ExecutionResultFailed) {
+			setRunResult(iRun, jobName, previous)
+			errs = append(errs, markObserved(ctx, clientset, &jobs[i], previous.Result))
+			continue
+		}
+		record, runnerMetrics := computeExecutionRecord(ctx, clientset, &jobs[i])
+		if err := collectLogs(ctx, clientset, iRun, logCollection, &jobs[i], &record); err != nil {
+			record.Logs = fmt.Sprintf("unable to collect logs: %v", err)
+		}
+		if ok && (previous.StartTime == nil || previous.StartTime.Equal(jobs[i].Status.StartTime)) {
+			observeExecution(iRun, &jobs[i], &previous, record, runnerMetrics)
+			recordExecutionEvents(rec, iRun, &previous, record)
+		} else {
+			observeExecution(iRun, &jobs[i], nil, record, runnerMetrics)
+			recordExecutionEvents(rec, iRun, nil, record)
+		}
+		records[jobs[i].Name] = record
+		setRunResult(iRun, jobName, record)
+		if record.Result == controllerapi.ExecutionResultSucceeded || record.Result == controllerapi.ExecutionResultFailed {
+			errs = append(errs, markObserved(ctx, clientset, &jobs[i], record.Result))
+		}
+	}
+
+	active := 0
+	for _, record := range records {
+		if record.Result == controllerapi.ExecutionResultRunning {
+			active++
+		}
+	}
+	setActiveExecutions(iRun, active)
+
+	history := make([]controllerapi.ExecutionRecord, 0, len(records))
+	for _, record := range records {
+		history = append(history, record)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return recordTime(history[j]).Before(recordTime(history[i]))
+	})
+
+	for _, record := range history {
+		if record.CompletionTime == nil {
+			continue
+		}
+		switch record.Result {
+		case controllerapi.ExecutionResultSucceeded:
+			if iRun.Status.LastSuccessfulTime == nil || iRun.Status.LastSuccessfulTime.Before(record.CompletionTime) {
+				iRun.Status.LastSuccessfulTime = record.CompletionTime.DeepCopy()
+			}
+		case controllerapi.ExecutionResultFailed:
+			if iRun.Status.LastFailureTime == nil || iRun.Status.LastFailureTime.Before(record.CompletionTime) {
+				iRun.Status.LastFailureTime = record.CompletionTime.DeepCopy()
+			}
+		}
+	}
+
+	var removed []controllerapi.ExecutionRecord
+	if limit := iRun.Spec.GetHistoryLimit(); len(history) > limit {
+		removed = history[limit:]
+		history = history[:limit]
+	}
+	if len(history) == 0 {
+		history = nil
+	}
+	iRun.Status.History = history
+	errs = append(errs, deleteLogsConfigMaps(ctx, clientset, iRun.Namespace, removed))
+	return errors.Join(errs...)
+}
+
+// setRunResult reports in the status the result of the Job of the InferenceRun without schedule
+func setRunResult(iRun *controllerapi.InferenceRun, jobName string, record controllerapi.ExecutionRecord) {
+	if iRun.Spec.Schedule != nil || record.JobName != jobName {
+		return
+	}
+	iRun.Status.Result = record.Result
+	iRun.Status.CompletionTime = record.CompletionTime.DeepCopy()
+}
+
+// markObserved sets the OBSERVED_ANNOTATION on the finished Job
+func markObserved(ctx context.Context, clientset kubernetes.Interface, job *v1batch.Job, result controllerapi.ExecutionResult) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{OBSERVED_ANNOTATION: string(result)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = clientset.BatchV1().Jobs(job.Namespace).Patch(ctx, job.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not annotate job %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// listRunJobs returns the one-shot Job of the InferenceRun or the Jobs owned by its CronJob, and its backfill and matrix Jobs
+func listRunJobs(ctx context.Context, clientset kubernetes.Interface, jobName string, iRun *controllerapi.InferenceRun) ([]v1batch.Job, error) {
+	jobClient := clientset.BatchV1().Jobs(iRun.Namespace)
+	jobs := []v1batch.Job{}
+	if iRun.Spec.Schedule == nil {
+		job, err := jobClient.Get(ctx, jobName, metav1.GetOptions{})
+		if err == nil {
+			jobs = append(jobs, *job)
+		}
+	} else if cronJob, err := clientset.BatchV1().CronJobs(iRun.Namespace).Get(ctx, jobName, metav1.GetOptions{}); err == nil {
+		// Only CronJobs created by previous versions, without the RUN_LABEL in the job template, need all the Jobs of
+		// the namespace to be listed
+		listOptions := metav1.ListOptions{}
+		if cronJob.Spec.JobTemplate.Labels[RUN_LABEL] == jobName {
+			listOptions.LabelSelector = fmt.Sprintf("%s=%s", RUN_LABEL, jobName)
+		}
+		jobList, err := jobClient.List(ctx, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("could not list jobs of cronjob %s: %w", jobName, err)
+		}
+		for _, job := range jobList.Items {
+			if owner := metav1.GetControllerOf(&job); owner != nil && owner.UID == cronJob.UID {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	if iRun.Spec.Matrix != nil {
+		matrixJobs, err := listMatrixJobs(ctx, clientset, jobName, iRun)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range matrixJobs {
+			jobs = append(jobs, job)
+		}
+	}
+	if iRun.Spec.Backfill != nil {
+		backfillJobs, err := listBackfillJobs(ctx, clientset, jobName, iRun)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range backfillJobs {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// computeExecutionRecord returns the record of the execution of the Job and, once finished, the metrics reported by
+// its runner pods
+func computeExecutionRecord(ctx context.Context, clientset kubernetes.Interface, job *v1batch.Job) (controllerapi.ExecutionRecord, []jobHelper.RunnerMetrics) {
+	record := controllerapi.ExecutionRecord{
+		JobName:   job.Name,
+		StartTime: job.Status.StartTime,
+	}
+	switch computeJobStatus(job) {
+	case JobStatusSucceeded:
+		record.Result = controllerapi.ExecutionResultSucceeded
+		record.CompletionTime = job.Status.CompletionTime
+	case JobStatusFailed:
+		record.Result = controllerapi.ExecutionResultFailed
+		for _, cond := range job.Status.Conditions {
+			if cond.Type == v1batch.JobFailed && cond.Status == v1.ConditionTrue {
+				record.FailureReason = fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+				record.CompletionTime = cond.LastTransitionTime.DeepCopy()
+			}
+		}
+		if record.FailureReason == "" {
+			record.FailureReason = "unknown error"
+		}
+	case JobStatusRunning:
+		record.Result = controllerapi.ExecutionResultRunning
+	default:
+		record.Result = controllerapi.ExecutionResultPending
+	}
+
+	var runnerMetrics []jobHelper.RunnerMetrics
+	if record.Result == controllerapi.ExecutionResultSucceeded || record.Result == controllerapi.ExecutionResultFailed {
+		record.ResultSummary, runnerMetrics = getRunnerReports(ctx, clientset, job)
+	}
+	return record, runnerMetrics
+}
+
+// getRunnerReports returns the summary in the termination message of the last terminated runner container of the
+// Job and the metrics reported by all its runner containers, one for each pod of sharded Jobs
+func getRunnerReports(ctx context.Context, clientset kubernetes.Interface, job *v1batch.Job) (string, []jobHelper.RunnerMetrics) {
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1batch.JobNameLabel, job.Name),
+	})
+	if err != nil {
+		return "", nil
+	}
+
+	summary := ""
+	runnerMetrics := []jobHelper.RunnerMetrics{}
+	var finishedAt metav1.Time
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != jobHelper.RUNNER_CONTAINER_NAME || status.State.Terminated == nil {
+				continue
+			}
+			report := jobHelper.ParseTerminationMessage(status.State.Terminated.Message)
+			if report.Metrics != nil {
+				runnerMetrics = append(runnerMetrics, *report.Metrics)
+			}
+			if summary == "" || finishedAt.Before(&status.State.Terminated.FinishedAt) {
+				summary = report.Summary
+				finishedAt = status.State.Terminated.FinishedAt
+			}
+		}
+	}
+	if len(summary) > RESULT_SUMMARY_MAX_LENGTH {
+		summary = summary[:RESULT_SUMMARY_MAX_LENGTH]
+	}
+	return summary, runnerMetrics
+}
+
+func recordTime(record controllerapi.ExecutionRecord) *metav1.Time {
+	if record.StartTime != nil {
+		return record.StartTime
+	}
+	if record.CompletionTime != nil {
+		return record.CompletionTime
+	}
+	return &metav1.Time{}
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func finishedMatrixJob(namespace string, jobName string, index int, start time.Time) *v1batch.Job {
+	completion := metav1.NewTime(start.Add(time.Minute))
+	return &v1batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s-m%d", jobName, index),
+			Namespace:         namespace,
+			CreationTimestamp: metav1.NewTime(start),
+			Labels: map[string]string{
+				MATRIX_LABEL:             jobName,
+				MATRIX_COMBINATION_LABEL: fmt.Sprint(index),
+			},
+		},
+		Status: v1batch.JobStatus{
+			StartTime:      &metav1.Time{Time: start},
+			CompletionTime: &completion,
+			Succeeded:      1,
+			Conditions: []v1batch.JobCondition{
+				{Type: v1batch.JobComplete, Status: v1.ConditionTrue, LastTransitionTime: completion},
+			},
+		},
+	}
+}
+
+func TestRecordHistoryBeyondHistoryLimit(t *testing.T) {
+	tests := map[string]struct {
+		jobs         int
+		historyLimit int32
+	}{
+		"more jobs than history limit": {jobs: 5, historyLimit: 2},
+		"history disabled":             {jobs: 3, historyLimit: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			namespace := strings.ReplaceAll(name, " ", "-")
+			jobName := "inf-forecast-1234"
+			iRun := &controllerapi.InferenceRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: namespace, UID: "1234"},
+				Spec: controllerapi.InferenceRunSpec{
+					ConfigRef:    &finopsdatatypes.ObjectRef{Name: "forecast", Namespace: namespace},
+					HistoryLimit: ptr.To(tc.historyLimit),
+					Matrix:       &controllerapi.MatrixSpec{Parameters: map[string][]string{"key": {"a"}}},
+				},
+			}
+			objects := []runtime.Object{}
+			start := time.Now().Add(-time.Hour).Truncate(time.Second)
+			for i := 0; i < tc.jobs; i++ {
+				objects = append(objects, finishedMatrixJob(namespace, jobName, i, start.Add(time.Duration(i)*time.Minute)))
+			}
+			clientset := fake.NewSimpleClientset(objects...)
+			rec := record.NewFakeRecorder(100)
+
+			for reconcile := 0; reconcile < 3; reconcile++ {
+				if err := recordHistory(context.Background(), clientset, jobName, iRun, nil, rec); err != nil {
+					t.Fatalf("reconcile %d: %v", reconcile, err)
+				}
+				if len(iRun.Status.History) != int(tc.historyLimit) {
+					t.Fatalf("reconcile %d: expected %d records, got %d", reconcile, tc.historyLimit, len(iRun.Status.History))
+				}
+			}
+
+			succeeded := testutil.ToFloat64(runsTotal.WithLabelValues(configRefKey(iRun), string(controllerapi.ExecutionResultSucceeded), ""))
+			if succeeded != float64(tc.jobs) {
+				t.Errorf("expected %d executions counted, got %v", tc.jobs, succeeded)
+			}
+			events := map[string]int{}
+			close(rec.Events)
+			for event := range rec.Events {
+				events[strings.Fields(event)[1]]++
+			}
+			if events[ReasonJobStarted] != tc.jobs || events[ReasonJobSucceeded] != tc.jobs {
+				t.Errorf("expected %d %s and %s events, got %v", tc.jobs, ReasonJobStarted, ReasonJobSucceeded, events)
+			}
+
+			jobs, err := clientset.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, job := range jobs.Items {
+				if job.Annotations[OBSERVED_ANNOTATION] != string(controllerapi.ExecutionResultSucceeded) {
+					t.Errorf("job %s is not annotated as observed: %v", job.Name, job.Annotations)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordHistoryRunResult(t *testing.T) {
+	namespace := "run-result"
+	jobName := "inf-forecast-5678"
+	iRun := &controllerapi.InferenceRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: namespace, UID: "5678"},
+		Spec: controllerapi.InferenceRunSpec{
+			ConfigRef:    &finopsdatatypes.ObjectRef{Name: "forecast", Namespace: namespace},
+			HistoryLimit: ptr.To(int32(0)),
+		},
+	}
+	job := finishedMatrixJob(namespace, jobName, 0, time.Now().Add(-time.Hour).Truncate(time.Second))
+	job.Name = jobName
+	job.Labels = nil
+	clientset := fake.NewSimpleClientset(job)
+
+	if err := recordHistory(context.Background(), clientset, jobName, iRun, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(iRun.Status.History) != 0 {
+		t.Fatalf("expected no records, got %d", len(iRun.Status.History))
+	}
+	if !runFinished(iRun) || iRun.Status.Result != controllerapi.ExecutionResultSucceeded {
+		t.Fatalf("expected the run to be finished with result Succeeded, got %q", iRun.Status.Result)
+	}
+	if completionTime := runCompletionTime(iRun); completionTime == nil || !completionTime.Equal(job.Status.CompletionTime) {
+		t.Errorf("expected completion time %v, got %v", job.Status.CompletionTime, completionTime)
+	}
+
+	// The Job deleted by its TTL does not change the result
+	if err := clientset.BatchV1().Jobs(namespace).Delete(context.Background(), jobName, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordHistory(context.Background(), clientset, jobName, iRun, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !runFinished(iRun) {
+		t.Errorf("expected the run to be still finished after the deletion of its Job")
+	}
+}
+
+func TestListRunJobs(t *testing.T) {
+	jobName := "inf-forecast-1234"
+	cronJob := func(labels map[string]string) *v1batch.CronJob {
+		return &v1batch.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: jobName, Namespace: "finops", UID: "cron"},
+			Spec: v1batch.CronJobSpec{
+				JobTemplate: v1batch.JobTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+			},
+		}
+	}
+	runJob := func(name string, labels map[string]string, owner *v1batch.CronJob) *v1batch.Job {
+		job := &v1batch.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "finops", Labels: labels}}
+		if owner != nil {
+			job.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, v1batch.SchemeGroupVersion.WithKind("CronJob"))}
+		}
+		return job
+	}
+	labelled := cronJob(map[string]string{RUN_LABEL: jobName})
+	legacy := cronJob(nil)
+
+	tests := map[string]struct {
+		objects  []runtime.Object
+		want     []string
+		selector string
+	}{
+		"jobs of the cronjob selected by label": {
+			objects: []runtime.Object{
+				labelled,
+				runJob(jobName+"-1", map[string]string{RUN_LABEL: jobName}, labelled),
+				runJob(jobName+"-manual-1", map[string]string{RUN_LABEL: jobName}, labelled),
+				runJob("other", nil, nil),
+			},
+			want:     []string{jobName + "-1", jobName + "-manual-1"},
+			selector: RUN_LABEL + "=" + jobName,
+		},
+		"cronjob of a previous version": {
+			objects: []runtime.Object{
+				legacy,
+				runJob(jobName+"-1", nil, legacy),
+				runJob("other", nil, nil),
+			},
+			want: []string{jobName + "-1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iRun := &controllerapi.InferenceRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: "finops"},
+				Spec:       controllerapi.InferenceRunSpec{Schedule: ptr.To("0 2 * * *")},
+			}
+			clientset := fake.NewSimpleClientset(tc.objects...)
+			jobs, err := listRunJobs(context.Background(), clientset, jobName, iRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, job := range jobs {
+				got = append(got, job.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("expected jobs %v, got %v", tc.want, got)
+			}
+			for _, action := range clientset.Actions() {
+				if list, ok := action.(k8stesting.ListAction); ok && list.GetResource().Resource == "jobs" {
+					if selector := list.GetListRestrictions().Labels.String(); selector != tc.selector {
+						t.Errorf("expected jobs listed with selector %q, got %q", tc.selector, selector)
+					}
+				}
+			}
+		})
+	}
+}
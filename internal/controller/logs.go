@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	controllerapi "kserve-controller/api/v1"
+	jobHelper "kserve-controller/internal/helpers/job"
+)
+
+const (
+	LOGS_STATUS_MAX_LENGTH    int    = 4 * 1024
+	LOGS_CONFIGMAP_MAX_LENGTH int    = 512 * 1024
+	LOGS_CONFIGMAP_SUFFIX     string = "-logs"
+	LOGS_CONFIGMAP_KEY        string = "runner.log"
+)
+
+// collectLogs stores the tail of the logs of the runner containers of the finished Job in the record, or in a
+// ConfigMap owned by the InferenceRun, according to the logCollection of the InferenceConfig
+func collectLogs(ctx context.Context, clientset kubernetes.Interface, iRun *controllerapi.InferenceRun, logCollection *controllerapi.LogCollectionSpec, job *v1batch.Job, record *controllerapi.ExecutionRecord) error {
+	if !logCollection.Collects(record.Result) {
+		return nil
+	}
+
+	logs, err := getRunnerLogs(ctx, clientset, job, logCollection.GetTailLines(), record.Result == controllerapi.ExecutionResultFailed)
+	if err != nil {
+		return err
+	}
+	if logs == "" {
+		return nil
+	}
+
+	if logCollection.Destination != controllerapi.LogDestinationConfigMap {
+		record.Logs = truncateTail(logs, LOGS_STATUS_MAX_LENGTH)
+		return nil
+	}
+
+	configmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + LOGS_CONFIGMAP_SUFFIX,
+			Namespace: job.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(iRun, controllerapi.GroupVersion.WithKind("InferenceRun")),
+			},
+		},
+		Data: map[string]string{
+			LOGS_CONFIGMAP_KEY: truncateTail(logs, LOGS_CONFIGMAP_MAX_LENGTH),
+		},
+	}
+	cmClient := clientset.CoreV1().ConfigMaps(job.Namespace)
+	_, err = cmClient.Create(ctx, configmap, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = cmClient.Update(ctx, configmap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("could not store logs of job %s in configmap %s: %w", job.Name, configmap.Name, err)
+	}
+	record.LogsConfigMap = configmap.Name
+	return nil
+}
+
+// getRunnerLogs returns the tail of the logs of the terminated runner containers of the Job, one section for each pod.
+// When failedOnly is true, only the pods whose runner failed are collected, if any.
+func getRunnerLogs(ctx context.Context, clientset kubernetes.Interface, job *v1batch.Job, tailLines int64, failedOnly bool) (string, error) {
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", v1batch.JobNameLabel, job.Name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not list pods of job %s: %w", job.Name, err)
+	}
+
+	terminated := []v1.Pod{}
+	failed := []v1.Pod{}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != jobHelper.RUNNER_CONTAINER_NAME || status.State.Terminated == nil {
+				continue
+			}
+			terminated = append(terminated, pod)
+			if status.State.Terminated.ExitCode != 0 {
+				failed = append(failed, pod)
+			}
+		}
+	}
+	if failedOnly && len(failed) > 0 {
+		terminated = failed
+	}
+	sort.Slice(terminated, func(i, j int) bool {
+		return terminated[i].Name < terminated[j].Name
+	})
+
+	sections := []string{}
+	for _, pod := range terminated {
+		logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+			Container: jobHelper.RUNNER_CONTAINER_NAME,
+			TailLines: &tailLines,
+		}).DoRaw(ctx)
+		if err != nil {
+			return "", fmt.Errorf("could not get logs of pod %s: %w", pod.Name, err)
+		}
+		if len(terminated) > 1 {
+			sections = append(sections, fmt.Sprintf("==> %s <==\n%s", pod.Name, logs))
+		} else {
+			sections = append(sections, string(logs))
+		}
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+// deleteLogsConfigMaps deletes the ConfigMaps with the logs of the records removed from the history
+func deleteLogsConfigMaps(ctx context.Context, clientset kubernetes.Interface, namespace string, records []controllerapi.ExecutionRecord) error {
+	for _, record := range records {
+		if record.LogsConfigMap == "" {
+			continue
+		}
+		err := clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, record.LogsConfigMap, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete configmap %s: %w", record.LogsConfigMap, err)
+		}
+	}
+	return nil
+}
+
+// truncateTail keeps the last max bytes of the logs
+func truncateTail(logs string, max int) string {
+	if len(logs) <= max {
+		return logs
+	}
+	return logs[len(logs)-max:]
+}
@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+	"kserve-controller/internal/helpers/parameters"
+
+	v1batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	MATRIX_LABEL             string = "ai.krateo.io/matrix"
+	MATRIX_COMBINATION_LABEL string = "ai.krateo.io/matrix-combination"
+	MATRIX_MAX_COMBINATIONS  int    = 1000
+)
+
+// getMatrixCombinations returns the combinations of the values of the matrix parameters, by combination id
+func getMatrixCombinations(ctx context.Context, iRun *controllerapi.InferenceRun) (map[string]map[string]string, error) {
+	if iRun.Spec.Schedule != nil || iRun.Spec.Backfill != nil {
+		return nil, fmt.Errorf("matrix is not supported with schedule and backfill")
+	}
+
+	values := map[string][]string{}
+	for name, list := range iRun.Spec.Matrix.Parameters {
+		values[name] = list
+	}
+	for _, source := range iRun.Spec.Matrix.ParametersFrom {
+		var raw string
+		var err error
+		switch {
+		case source.ConfigMapKeyRef != nil:
+			raw, err = getConfigMapKey(ctx, iRun.Namespace, source.ConfigMapKeyRef)
+		case source.SecretKeyRef != nil:
+			// Matrix values are copied in the contract of the Jobs, which must not carry credentials
+			err = fmt.Errorf("secretKeyRef is not supported in matrix parameters")
+		default:
+			err = fmt.Errorf("no source")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve matrix parameter %s: %w", source.Name, err)
+		}
+		values[source.Name] = append(values[source.Name], parseMatrixValues(raw)...)
+	}
+
+	names := make([]string, 0, len(values))
+	total := 1
+	for name, list := range values {
+		if len(list) == 0 {
+			return nil, fmt.Errorf("matrix parameter %s has no values", name)
+		}
+		names = append(names, name)
+		total *= len(list)
+		if total > MATRIX_MAX_COMBINATIONS {
+			return nil, fmt.Errorf("matrix exceeds the maximum of %d combinations", MATRIX_MAX_COMBINATIONS)
+		}
+	}
+	sort.Strings(names)
+
+	combinations := []map[string]string{{}}
+	for _, name := range names {
+		expanded := make([]map[string]string, 0, len(combinations)*len(values[name]))
+		for _, combination := range combinations {
+			for _, value := range values[name] {
+				next := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					next[k] = v
+				}
+				next[name] = value
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+
+	byId := make(map[string]map[string]string, len(combinations))
+	for _, combination := range combinations {
+		if len(combination) == 0 {
+			continue
+		}
+		// Maps are marshalled with sorted keys
+		b, err := json.Marshal(combination)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(b)
+		byId[hex.EncodeToString(sum[:])[:8]] = combination
+	}
+	return byId, nil
+}
+
+// parseMatrixValues parses a JSON array of strings, or one value per line
+func parseMatrixValues(raw string) []string {
+	values := []string{}
+	if err := json.Unmarshal([]byte(raw), &values); err == nil {
+		return values
+	}
+	values = []string{}
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+func getMatrixJobName(jobName string, combinationId string) string {
+	suffix := "-" + combinationId
+	if len(jobName) > 63-len(suffix) {
+		jobName = jobName[:63-len(suffix)]
+	}
+	return jobName + suffix
+}
+
+// listMatrixJobs returns the Jobs of the matrix of the InferenceRun, by combination id
+func listMatrixJobs(ctx context.Context, clientset kubernetes.Interface, jobName string, iRun *controllerapi.InferenceRun) (map[string]v1batch.Job, error) {
+	jobList, err := clientset.BatchV1().Jobs(iRun.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", MATRIX_LABEL, jobName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list matrix jobs: %w", err)
+	}
+	jobs := map[string]v1batch.Job{}
+	for _, job := range jobList.Items {
+		jobs[job.Labels[MATRIX_COMBINATION_LABEL]] = job
+	}
+	return jobs, nil
+}
+
+// observeMatrix updates the aggregated status of the matrix Jobs of the InferenceRun and returns true if Jobs must be
+// created for the remaining combinations
+func observeMatrix(ctx context.Context, jobName string, iRun *controllerapi.InferenceRun) (bool, error) {
+	if iRun.Spec.Matrix == nil {
+		iRun.Status.Matrix = nil
+		return false, nil
+	}
+
+	combinations, err := getMatrixCombinations(ctx, iRun)
+	if err != nil {
+		return false, err
+	}
+
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return false, fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, err
+	}
+	allJobs, err := listMatrixJobs(ctx, clientset, jobName, iRun)
+	if err != nil {
+		return false, err
+	}
+	// Jobs of combinations removed from the matrix are not counted
+	jobs := map[string]v1batch.Job{}
+	for id := range combinations {
+		if job, ok := allJobs[id]; ok {
+			jobs[id] = job
+		}
+	}
+
+	status := &controllerapi.MatrixStatus{
+		Combinations: int32(len(combinations)),
+	}
+	status.Active, status.Succeeded, status.Failed = countJobs(jobs)
+	if status.Succeeded+status.Failed == status.Combinations {
+		status.CompletionTime = &metav1.Time{Time: time.Now()}
+		if iRun.Status.Matrix != nil && iRun.Status.Matrix.CompletionTime != nil {
+			status.CompletionTime = iRun.Status.Matrix.CompletionTime
+		}
+	}
+	iRun.Status.Matrix = status
+
+	return len(jobs) < len(combinations) && status.Active < iRun.Spec.Matrix.GetMaxParallelism(), nil
+}
+
+// runMatrix creates the Jobs of the combinations without a Job, up to the max parallelism of the matrix
+func runMatrix(ctx context.Context, jobName string, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig) ([]string, error) {
+	combinations, err := getMatrixCombinations(ctx, iRun)
+	if err != nil {
+		return nil, err
+	}
+
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return nil, fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := listMatrixJobs(ctx, clientset, jobName, iRun)
+	if err != nil {
+		return nil, err
+	}
+	active, _, _ := countJobs(jobs)
+
+	ids := make([]string, 0, len(combinations))
+	for id := range combinations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	created := []string{}
+	for _, id := range ids {
+		if active >= iRun.Spec.Matrix.GetMaxParallelism() {
+			break
+		}
+		if _, ok := jobs[id]; ok {
+			continue
+		}
+
+		contract := job.ContractSpec{}
+		err := json.Unmarshal(iRun.Status.Contract, &contract)
+		if err != nil {
+			return created, fmt.Errorf("could not unmarshal contract: %w", err)
+		}
+		params := map[string]string{}
+		if contract.Parameters != nil {
+			for k, v := range *contract.Parameters {
+				params[k] = v
+			}
+		}
+		for k, v := range combinations[id] {
+			params[k] = v
+		}
+		contract.Parameters = &params
+		unresolved := []string{}
+		for name := range contract.ParameterTemplates {
+			unresolved = append(unresolved, name)
+		}
+		for name := range contract.SecretParameters {
+			unresolved = append(unresolved, name)
+		}
+		err = parameters.ValidateSchema(iConf.Spec.Parameters, params, unresolved)
+		if err != nil {
+			return created, fmt.Errorf("invalid matrix combination %v: %w", combinations[id], err)
+		}
+
+		matrixJobName := getMatrixJobName(jobName, id)
+		labels := map[string]string{
+			RUN_LABEL:                jobName,
+			MATRIX_LABEL:             jobName,
+			MATRIX_COMBINATION_LABEL: id,
+		}
+		err = createChildJob(ctx, clientset, matrixJobName, labels, contract, iRun, iConf)
+		if err != nil {
+			return created, fmt.Errorf("could not create matrix job %s: %w", matrixJobName, err)
+		}
+		created = append(created, matrixJobName)
+		active++
+	}
+	return created, nil
+}
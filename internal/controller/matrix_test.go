@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func TestGetMatrixCombinations(t *testing.T) {
+	values := func(n int) []string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = fmt.Sprint(i)
+		}
+		return list
+	}
+
+	tests := map[string]struct {
+		spec    controllerapi.InferenceRunSpec
+		want    []map[string]string
+		count   int
+		wantErr bool
+	}{
+		"single parameter": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{"region": {"eu", "us"}}},
+			},
+			want: []map[string]string{{"region": "eu"}, {"region": "us"}},
+		},
+		"cartesian product": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{
+					"region": {"eu", "us"},
+					"model":  {"a", "b", "c"},
+				}},
+			},
+			want: []map[string]string{
+				{"model": "a", "region": "eu"}, {"model": "a", "region": "us"},
+				{"model": "b", "region": "eu"}, {"model": "b", "region": "us"},
+				{"model": "c", "region": "eu"}, {"model": "c", "region": "us"},
+			},
+		},
+		"no parameters": {
+			spec: controllerapi.InferenceRunSpec{Matrix: &controllerapi.MatrixSpec{}},
+			want: []map[string]string{},
+		},
+		"maximum number of combinations": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{
+					"a": values(10), "b": values(10), "c": values(10),
+				}},
+			},
+			count: MATRIX_MAX_COMBINATIONS,
+		},
+		"more than the maximum number of combinations": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{
+					"a": values(10), "b": values(10), "c": values(11),
+				}},
+			},
+			wantErr: true,
+		},
+		"parameter without values": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{"region": {}}},
+			},
+			wantErr: true,
+		},
+		"secretKeyRef": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{ParametersFrom: []controllerapi.ParameterSource{
+					{Name: "token", SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "tokens"}, Key: "token"}},
+				}},
+			},
+			wantErr: true,
+		},
+		"source without reference": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{ParametersFrom: []controllerapi.ParameterSource{{Name: "region"}}},
+			},
+			wantErr: true,
+		},
+		"with schedule": {
+			spec: controllerapi.InferenceRunSpec{
+				Schedule: ptr.To("0 2 * * *"),
+				Matrix:   &controllerapi.MatrixSpec{Parameters: map[string][]string{"region": {"eu"}}},
+			},
+			wantErr: true,
+		},
+		"with backfill": {
+			spec: controllerapi.InferenceRunSpec{
+				Backfill: &controllerapi.BackfillSpec{},
+				Matrix:   &controllerapi.MatrixSpec{Parameters: map[string][]string{"region": {"eu"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iRun := &controllerapi.InferenceRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: "finops"},
+				Spec:       tc.spec,
+			}
+			combinations, err := getMatrixCombinations(context.Background(), iRun)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d combinations", len(combinations))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.count != 0 {
+				if len(combinations) != tc.count {
+					t.Errorf("expected %d combinations, got %d", tc.count, len(combinations))
+				}
+				return
+			}
+			got := make([]map[string]string, 0, len(combinations))
+			for _, combination := range combinations {
+				got = append(got, combination)
+			}
+			sort.Slice(got, func(i, j int) bool { return fmt.Sprint(got[i]) < fmt.Sprint(got[j]) })
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+
+			// The ids are stable between reconciles, since they name the Jobs of the combinations
+			again, err := getMatrixCombinations(context.Background(), iRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, combinations) {
+				t.Errorf("combination ids changed between calls: %v and %v", combinations, again)
+			}
+		})
+	}
+}
+
+func TestParseMatrixValues(t *testing.T) {
+	tests := map[string]struct {
+		raw  string
+		want []string
+	}{
+		"JSON array": {
+			raw:  `["eu", "us"]`,
+			want: []string{"eu", "us"},
+		},
+		"one value per line": {
+			raw:  "eu\n  us  \n\nasia\n",
+			want: []string{"eu", "us", "asia"},
+		},
+		"single value": {
+			raw:  "eu",
+			want: []string{"eu"},
+		},
+		"JSON array of numbers read as lines": {
+			raw:  "[1, 2]",
+			want: []string{"[1, 2]"},
+		},
+		"empty": {
+			raw:  "",
+			want: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := parseMatrixValues(tc.raw); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	controllerapi "kserve-controller/api/v1"
+	jobHelper "kserve-controller/internal/helpers/job"
+)
+
+const (
+	CONTRACT_ERROR_STORAGE    string = "storage"
+	CONTRACT_ERROR_PARAMETERS string = "parameters"
+	CONTRACT_ERROR_MARSHAL    string = "marshal"
+)
+
+var (
+	runsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "inferencerun_total",
+		Help: "Number of finished executions of the InferenceRuns, by InferenceConfig, result and failure reason",
+	}, []string{"config", "result", "reason"})
+
+	runDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "inferencerun_duration_seconds",
+		Help:    "Duration of the finished executions of the InferenceRuns, from the start to the completion of their Job",
+		Buckets: prometheus.ExponentialBuckets(5, 2, 12),
+	}, []string{"config", "result"})
+
+	runQueueWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "inferencerun_queue_wait_seconds",
+		Help:    "Time between the creation and the start of the Jobs of the InferenceRuns, including the time spent suspended by a queue",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"config"})
+
+	activeRuns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "inferencerun_active",
+		Help: "Number of running executions of the InferenceRuns",
+	}, []string{"config"})
+
+	runRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "inferencerun_retries_total",
+		Help: "Number of failed Jobs re-created by the controller",
+	}, []string{"config"})
+
+	contractErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "inferencerun_contract_errors_total",
+		Help: "Number of reconciles that could not compute the contract of an InferenceRun, by InferenceConfig and reason",
+	}, []string{"config", "reason"})
+)
+
+// Metrics reported by the runners in their termination message, observed once for each runner pod
+var (
+	runnerInputFetch = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "inferencerun_runner_input_fetch_seconds",
+		Help:    "Time spent by the runners loading the input data",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"config"})
+
+	runnerInference = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "inferencerun_runner_inference_seconds",
+		Help:    "Latency of the calls of the runners to the model, for each batch",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"config"})
+
+	runnerOutputWrite = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "inferencerun_runner_output_write_seconds",
+		Help:    "Time spent by the runners storing the predictions",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"config"})
+
+	runnerRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "inferencerun_runner_rows_total",
+		Help: "Number of input rows processed and predictions stored by the runners",
+	}, []string{"config", "direction"})
+
+	runnerBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "inferencerun_runner_payload_bytes_total",
+		Help: "Size of the payloads loaded and stored by the runners",
+	}, []string{"config", "direction"})
+)
+
+// Running executions of each InferenceRun, the gauge is the sum by InferenceConfig
+var (
+	activeMu    sync.Mutex
+	activeByRun = map[types.UID]activeEntry{}
+)
+
+type activeEntry struct {
+	config string
+	count  int
+}
+
+func init() {
+	metrics.Registry.MustRegister(runsTotal, runDuration, runQueueWait, activeRuns, runRetries, contractErrors,
+		runnerInputFetch, runnerInference, runnerOutputWrite, runnerRows, runnerBytes)
+}
+
+// observeExecution records the metrics of an execution when its record changes. Executions already recorded as
+// finished, in the status or with the OBSERVED_ANNOTATION on their Job, are not counted again, so that restarts of
+// the controller and records leaving the history do not count them twice.
+func observeExecution(iRun *controllerapi.InferenceRun, job *v1batch.Job, previous *controllerapi.ExecutionRecord, record controllerapi.ExecutionRecord, runnerMetrics []jobHelper.RunnerMetrics) {
+	config := configRefKey(iRun)
+
+	if record.StartTime != nil && (previous == nil || previous.StartTime == nil) {
+		runQueueWait.WithLabelValues(config).Observe(record.StartTime.Sub(job.CreationTimestamp.Time).Seconds())
+	}
+
+	if record.Result != controllerapi.ExecutionResultSucceeded && record.Result != controllerapi.ExecutionResultFailed {
+		return
+	}
+	reason := ""
+	if record.Result == controllerapi.ExecutionResultFailed {
+		reason, _, _ = strings.Cut(record.FailureReason, ":")
+	}
+	runsTotal.WithLabelValues(config, string(record.Result), reason).Inc()
+	if record.StartTime != nil && record.CompletionTime != nil {
+		runDuration.WithLabelValues(config, string(record.Result)).Observe(record.CompletionTime.Sub(record.StartTime.Time).Seconds())
+	}
+	for _, m := range runnerMetrics {
+		observeRunnerMetrics(config, m)
+	}
+}
+
+func observeRunnerMetrics(config string, m jobHelper.RunnerMetrics) {
+	if m.InputFetchSeconds > 0 {
+		runnerInputFetch.WithLabelValues(config).Observe(m.InputFetchSeconds)
+	}
+	for _, seconds := range m.InferenceSeconds {
+		runnerInference.WithLabelValues(config).Observe(seconds)
+	}
+	if m.OutputWriteSeconds > 0 {
+		runnerOutputWrite.WithLabelValues(config).Observe(m.OutputWriteSeconds)
+	}
+	runnerRows.WithLabelValues(config, "input").Add(float64(m.InputRows))
+	runnerRows.WithLabelValues(config, "output").Add(float64(m.Predictions))
+	runnerBytes.WithLabelValues(config, "input").Add(float64(m.InputBytes))
+	runnerBytes.WithLabelValues(config, "output").Add(float64(m.OutputBytes))
+}
+
+// setActiveExecutions updates the number of running executions of the InferenceRun
+func setActiveExecutions(iRun *controllerapi.InferenceRun, count int) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	if previous, ok := activeByRun[iRun.UID]; ok {
+		activeRuns.WithLabelValues(previous.config).Sub(float64(previous.count))
+	}
+	if count == 0 || iRun.Spec.ConfigRef == nil {
+		delete(activeByRun, iRun.UID)
+		return
+	}
+	entry := activeEntry{config: configRefKey(iRun), count: count}
+	activeByRun[iRun.UID] = entry
+	activeRuns.WithLabelValues(entry.config).Add(float64(count))
+}
+
+func observeRetry(iRun *controllerapi.InferenceRun) {
+	runRetries.WithLabelValues(configRefKey(iRun)).Inc()
+}
+
+func observeContractError(iRun *controllerapi.InferenceRun, reason string) {
+	contractErrors.WithLabelValues(configRefKey(iRun), reason).Inc()
+}
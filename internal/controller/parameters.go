@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+	"kserve-controller/internal/helpers/parameters"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const PARAMETER_ENV_PREFIX = "PARAMETER_"
+
+// computeContractParameters sets the parameters of the contract from the parameters of the InferenceRun.
+// Templates are rendered with the given scheduled time; when it is nil, as for CronJobs, they are left to the runner.
+// ConfigMap sources are resolved by the controller, Secret sources are only referenced by environment variable.
+// Parameters not set take the defaults of the schema of the InferenceConfig, the result is validated against it and
+// a *parameters.ValidationError is returned if it does not match.
+func computeContractParameters(ctx context.Context, contract *job.ContractSpec, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig, scheduledTime *time.Time) error {
+	contract.Parameters = nil
+	contract.ParameterTemplates = nil
+	contract.SecretParameters = nil
+
+	values := map[string]string{}
+	if iRun.Spec.Parameters != nil {
+		for name, value := range *iRun.Spec.Parameters {
+			values[name] = value
+		}
+	}
+	defaults := parameters.Defaults(iConf.Spec.Parameters, func(name string) bool {
+		if _, ok := values[name]; ok {
+			return true
+		}
+		return slices.ContainsFunc(iRun.Spec.ParametersFrom, func(source controllerapi.ParameterSource) bool {
+			return source.Name == name
+		})
+	})
+	for name, value := range defaults {
+		values[name] = value
+	}
+
+	rendered := map[string]string{}
+	templates := map[string]string{}
+	for name, value := range values {
+		if !parameters.IsTemplate(value) {
+			rendered[name] = value
+			continue
+		}
+		if scheduledTime == nil {
+			if err := parameters.Validate(name, value); err != nil {
+				return err
+			}
+			templates[name] = value
+			continue
+		}
+		renderedValue, err := parameters.Render(name, value, parameters.TemplateData{
+			ScheduledTime: *scheduledTime,
+			RunName:       contract.RunName,
+			Namespace:     contract.Namespace,
+			JobId:         contract.JobId,
+			JobName:       contract.JobName,
+		})
+		if err != nil {
+			return err
+		}
+		rendered[name] = renderedValue
+	}
+
+	secretParameters := map[string]string{}
+	for _, source := range iRun.Spec.ParametersFrom {
+		switch {
+		case source.ConfigMapKeyRef != nil:
+			value, err := getConfigMapKey(ctx, iRun.Namespace, source.ConfigMapKeyRef)
+			if err != nil {
+				return fmt.Errorf("could not resolve parameter %s: %w", source.Name, err)
+			}
+			rendered[source.Name] = value
+		case source.SecretKeyRef != nil:
+			secretParameters[source.Name] = getParameterEnvName(source.Name)
+		default:
+			return fmt.Errorf("parameter %s has no source", source.Name)
+		}
+	}
+
+	unresolved := make([]string, 0, len(templates)+len(secretParameters))
+	for name := range templates {
+		unresolved = append(unresolved, name)
+	}
+	for name := range secretParameters {
+		unresolved = append(unresolved, name)
+	}
+	if err := parameters.ValidateSchema(iConf.Spec.Parameters, rendered, unresolved); err != nil {
+		return err
+	}
+
+	// Runs without parameters keep the same contract
+	if iRun.Spec.Parameters != nil || len(rendered) > 0 {
+		contract.Parameters = &rendered
+	}
+	if len(templates) > 0 {
+		contract.ParameterTemplates = templates
+	}
+	if len(secretParameters) > 0 {
+		contract.SecretParameters = secretParameters
+	}
+	return nil
+}
+
+func getConfigMapKey(ctx context.Context, namespace string, ref *v1.ConfigMapKeySelector) (string, error) {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return "", fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	configmap, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if ref.Optional != nil && *ref.Optional {
+			return "", nil
+		}
+		return "", err
+	}
+	value, ok := configmap.Data[ref.Key]
+	if !ok && (ref.Optional == nil || !*ref.Optional) {
+		return "", fmt.Errorf("key %s not found in configmap %s", ref.Key, ref.Name)
+	}
+	return value, nil
+}
+
+// getParameterEnvName returns the environment variable of the runner container holding a parameter set from a Secret
+func getParameterEnvName(name string) string {
+	return PARAMETER_ENV_PREFIX + strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, name)
+}
+
+// getParameterEnv returns the environment variables of the parameters set from Secrets
+func getParameterEnv(iRun *controllerapi.InferenceRun) []v1.EnvVar {
+	env := []v1.EnvVar{}
+	for _, source := range iRun.Spec.ParametersFrom {
+		if source.SecretKeyRef == nil || source.ConfigMapKeyRef != nil {
+			continue
+		}
+		env = append(env, v1.EnvVar{
+			Name: getParameterEnvName(source.Name),
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: source.SecretKeyRef.DeepCopy(),
+			},
+		})
+	}
+	return env
+}
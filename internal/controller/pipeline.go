@@ -0,0 +1,678 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/krateoplatformops/provider-runtime/pkg/controller"
+	"github.com/krateoplatformops/provider-runtime/pkg/event"
+	"github.com/krateoplatformops/provider-runtime/pkg/logging"
+	"github.com/krateoplatformops/provider-runtime/pkg/ratelimiter"
+	"github.com/krateoplatformops/provider-runtime/pkg/reconciler"
+	"github.com/krateoplatformops/provider-runtime/pkg/resource"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	prv1 "github.com/krateoplatformops/provider-runtime/apis/common/v1"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers"
+	"kserve-controller/internal/helpers/config"
+	"kserve-controller/internal/helpers/job"
+	clientHelper "kserve-controller/internal/helpers/kube/client"
+)
+
+const (
+	PIPELINE_NAME_PREFIX string = "pl"
+	PIPELINE_LABEL       string = "ai.krateo.io/pipeline"
+	PIPELINE_STEP_LABEL  string = "ai.krateo.io/pipeline-step"
+)
+
+func SetupPipeline(mgr ctrl.Manager, o controller.Options, config config.Configuration) error {
+	name := reconciler.ControllerName(controllerapi.PipelineGroupKind)
+
+	log := o.Logger.WithValues("controller", name)
+	log.Info("controller", "name", name)
+
+	recorder := mgr.GetEventRecorderFor(name)
+
+	r := reconciler.NewReconciler(mgr,
+		resource.ManagedKind(controllerapi.PipelineGroupVersionKind),
+		reconciler.WithExternalConnecter(&pipelineConnector{
+			log:          log,
+			recorder:     recorder,
+			pollInterval: o.PollInterval,
+			config:       config,
+		}),
+		reconciler.WithPollInterval(o.PollInterval),
+		reconciler.WithLogger(log),
+		reconciler.WithRecorder(event.NewAPIRecorder(recorder)))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&controllerapi.InferencePipeline{}).
+		Owns(&controllerapi.InferenceRun{}).
+		Owns(&v1batch.Job{}).
+		Complete(ratelimiter.New(name, r, o.GlobalRateLimiter))
+}
+
+type pipelineConnector struct {
+	pollInterval time.Duration
+	log          logging.Logger
+	recorder     record.EventRecorder
+	config       config.Configuration
+}
+
+func (c *pipelineConnector) Connect(ctx context.Context, mg resource.Managed) (reconciler.ExternalClient, error) {
+	cfg := ctrl.GetConfigOrDie()
+
+	dynClient, err := clientHelper.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create dynamic client: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create clientset: %w", err)
+	}
+
+	return &pipelineExternal{
+		dynClient: dynClient,
+		clientset: clientset,
+		log:       c.log,
+		rec:       c.recorder,
+		config:    c.config,
+	}, nil
+}
+
+type pipelineExternal struct {
+	dynClient *dynamic.DynamicClient
+	clientset *kubernetes.Clientset
+	log       logging.Logger
+	rec       record.EventRecorder
+	config    config.Configuration
+	// observation is computed by Observe and used by Create and Update in the same reconcile
+	observation *pipelineObservation
+}
+
+// pipelineObservation is the state of the steps of a pipeline computed by observeSteps
+type pipelineObservation struct {
+	// ready are the steps ready to run
+	ready []string
+	// started is true if at least one step has been started
+	started bool
+	// failedRuns are the InferenceRuns of failed steps still to delete
+	failedRuns []*controllerapi.InferenceRun
+}
+
+func (c *pipelineExternal) Disconnect(_ context.Context) error {
+	return nil // NOOP
+}
+
+func (e *pipelineExternal) Observe(ctx context.Context, mg resource.Managed) (reconciler.ExternalObservation, error) {
+	pipeline, ok := mg.(*controllerapi.InferencePipeline)
+	if !ok {
+		return reconciler.ExternalObservation{}, fmt.Errorf("cannot cast to controllerapi.InferencePipeline")
+	}
+
+	log := e.log.WithValues("Reconcile", "Observe", "name", pipeline.Name, "namespace", pipeline.Namespace)
+
+	observation, err := e.observeSteps(ctx, pipeline)
+	if err != nil {
+		return reconciler.ExternalObservation{}, err
+	}
+	e.observation = observation
+
+	if !observation.started {
+		log.Info(fmt.Sprintf("%s does not have running steps yet", pipeline.Name))
+		return reconciler.ExternalObservation{
+			ResourceExists: false,
+		}, nil
+	}
+
+	if len(observation.failedRuns) > 0 {
+		log.Info(fmt.Sprintf("pipeline %s has failed steps to stop", pipeline.Name))
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: false,
+		}, nil
+	}
+
+	if len(observation.ready) > 0 && pipeline.Status.Phase != controllerapi.PipelinePhaseFailed {
+		log.Info(fmt.Sprintf("pipeline %s has steps ready to run: %v", pipeline.Name, observation.ready))
+		return reconciler.ExternalObservation{
+			ResourceExists:   true,
+			ResourceUpToDate: false,
+		}, nil
+	}
+
+	pipeline.SetConditions(prv1.Available())
+	return reconciler.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (e *pipelineExternal) Create(ctx context.Context, mg resource.Managed) error {
+	pipeline, ok := mg.(*controllerapi.InferencePipeline)
+	if !ok {
+		return fmt.Errorf("cannot cast to controllerapi.InferencePipeline")
+	}
+
+	pipeline.SetConditions(prv1.Creating())
+	return e.runReadySteps(ctx, pipeline)
+}
+
+func (e *pipelineExternal) Update(ctx context.Context, mg resource.Managed) error {
+	pipeline, ok := mg.(*controllerapi.InferencePipeline)
+	if !ok {
+		return fmt.Errorf("cannot cast to controllerapi.InferencePipeline")
+	}
+
+	if e.observation == nil {
+		return fmt.Errorf("pipeline %s has not been observed", pipeline.Name)
+	}
+	// InferenceRuns restart failed Jobs, the runs of the failed steps are deleted to stop the pipeline
+	for _, iRun := range e.observation.failedRuns {
+		if err := deleteRun(ctx, iRun); err != nil {
+			return fmt.Errorf("unable to delete failed InferenceRun %s: %w", iRun.Name, err)
+		}
+		e.log.Info(fmt.Sprintf("deleted failed InferenceRun %s of pipeline %s", iRun.Name, pipeline.Name))
+	}
+
+	return e.runReadySteps(ctx, pipeline)
+}
+
+func (e *pipelineExternal) Delete(ctx context.Context, mg resource.Managed) error {
+	pipeline, ok := mg.(*controllerapi.InferencePipeline)
+	if !ok {
+		return fmt.Errorf("cannot cast to controllerapi.InferencePipeline")
+	}
+
+	pipeline.SetConditions(prv1.Deleting())
+	// The InferenceRuns and Jobs of the steps are owned by the pipeline and are garbage collected with it
+	e.log.Info(fmt.Sprintf("receive delete for pipeline %s", pipeline.Name))
+	return nil
+}
+
+// runReadySteps starts the steps found ready by the last Observe
+func (e *pipelineExternal) runReadySteps(ctx context.Context, pipeline *controllerapi.InferencePipeline) error {
+	log := e.log.WithValues("Reconcile", "Run", "name", pipeline.Name, "namespace", pipeline.Namespace)
+
+	if e.observation == nil {
+		return fmt.Errorf("pipeline %s has not been observed", pipeline.Name)
+	}
+	if pipeline.Status.Phase == controllerapi.PipelinePhaseFailed {
+		return nil
+	}
+
+	steps := map[string]controllerapi.PipelineStep{}
+	for _, step := range pipeline.Spec.Steps {
+		steps[step.Name] = step
+	}
+	for _, name := range e.observation.ready {
+		var err error
+		step := steps[name]
+		if step.ConfigRef != nil {
+			err = e.createStepRun(ctx, pipeline, step, steps)
+		} else {
+			err = e.createStepJob(ctx, pipeline, step, steps)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to start step %s: %w", name, err)
+		}
+		log.Info(fmt.Sprintf("started step %s of pipeline %s", name, pipeline.Name))
+	}
+	return nil
+}
+
+// sortPipelineSteps validates the steps of the pipeline and returns them in topological order
+func sortPipelineSteps(pipeline *controllerapi.InferencePipeline) ([]controllerapi.PipelineStep, error) {
+	steps := map[string]controllerapi.PipelineStep{}
+	for _, step := range pipeline.Spec.Steps {
+		if _, ok := steps[step.Name]; ok {
+			return nil, fmt.Errorf("duplicate step %s", step.Name)
+		}
+		if (step.ConfigRef == nil) == (step.Container == nil) {
+			return nil, fmt.Errorf("step %s must have exactly one of configRef and container", step.Name)
+		}
+		steps[step.Name] = step
+	}
+
+	inDegree := map[string]int{}
+	dependents := map[string][]string{}
+	for _, step := range pipeline.Spec.Steps {
+		for _, dep := range getStepDependencies(step) {
+			if _, ok := steps[dep]; !ok {
+				return nil, fmt.Errorf("step %s depends on unknown step %s", step.Name, dep)
+			}
+			inDegree[step.Name]++
+			dependents[dep] = append(dependents[dep], step.Name)
+		}
+	}
+
+	sorted := make([]controllerapi.PipelineStep, 0, len(steps))
+	queue := []string{}
+	for _, step := range pipeline.Spec.Steps {
+		if inDegree[step.Name] == 0 {
+			queue = append(queue, step.Name)
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, steps[name])
+		for _, dependent := range dependents[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	if len(sorted) != len(steps) {
+		return nil, fmt.Errorf("steps have a dependency cycle")
+	}
+	return sorted, nil
+}
+
+// getStepDependencies returns the steps a step depends on, including the step of its input
+func getStepDependencies(step controllerapi.PipelineStep) []string {
+	deps := append([]string{}, step.DependsOn...)
+	if step.InputFrom != "" {
+		found := false
+		for _, dep := range deps {
+			found = found || dep == step.InputFrom
+		}
+		if !found {
+			deps = append(deps, step.InputFrom)
+		}
+	}
+	return deps
+}
+
+// observeSteps updates the status of the pipeline and its steps from the InferenceRuns and Jobs of the steps.
+// Finished steps keep their phase, even if their InferenceRun or Job is deleted.
+func (e *pipelineExternal) observeSteps(ctx context.Context, pipeline *controllerapi.InferencePipeline) (*pipelineObservation, error) {
+	sorted, err := sortPipelineSteps(pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+
+	previous := map[string]controllerapi.PipelineStepStatus{}
+	for _, status := range pipeline.Status.Steps {
+		previous[status.Name] = status
+	}
+
+	observation := &pipelineObservation{ready: []string{}}
+	phases := map[string]controllerapi.PipelinePhase{}
+	statuses := make([]controllerapi.PipelineStepStatus, 0, len(sorted))
+	for _, step := range sorted {
+		status, ok := previous[step.Name]
+		if !ok || (status.Phase != controllerapi.PipelinePhaseSucceeded && status.Phase != controllerapi.PipelinePhaseFailed) {
+			status, err = e.observeStep(ctx, pipeline, step, observation)
+			if err != nil {
+				return nil, err
+			}
+		} else if status.Phase == controllerapi.PipelinePhaseFailed && step.ConfigRef != nil {
+			// The InferenceRun of a step that failed in a previous reconcile may not have been deleted yet
+			iRun, err := e.getStepRun(ctx, pipeline, step)
+			if err != nil {
+				return nil, err
+			}
+			if iRun != nil {
+				observation.failedRuns = append(observation.failedRuns, iRun)
+			}
+		}
+
+		if status.Resource == nil && status.Phase == controllerapi.PipelinePhasePending {
+			depsSucceeded := true
+			for _, dep := range getStepDependencies(step) {
+				switch phases[dep] {
+				case controllerapi.PipelinePhaseFailed, controllerapi.PipelinePhaseSkipped:
+					status.Phase = controllerapi.PipelinePhaseSkipped
+					status.Message = fmt.Sprintf("step %s did not succeed", dep)
+				case controllerapi.PipelinePhaseSucceeded:
+				default:
+					depsSucceeded = false
+				}
+			}
+			if depsSucceeded && status.Phase == controllerapi.PipelinePhasePending {
+				observation.ready = append(observation.ready, step.Name)
+			}
+		}
+		if status.Resource != nil {
+			observation.started = true
+		}
+		phases[step.Name] = status.Phase
+		statuses = append(statuses, status)
+	}
+
+	phase := controllerapi.PipelinePhaseSucceeded
+	running := false
+	for _, status := range statuses {
+		switch status.Phase {
+		case controllerapi.PipelinePhaseFailed:
+			phase = controllerapi.PipelinePhaseFailed
+		case controllerapi.PipelinePhaseRunning:
+			running = true
+		}
+	}
+	if phase != controllerapi.PipelinePhaseFailed {
+		for _, status := range statuses {
+			if status.Phase != controllerapi.PipelinePhaseSucceeded {
+				phase = controllerapi.PipelinePhaseRunning
+			}
+		}
+	}
+	if !observation.started {
+		phase = controllerapi.PipelinePhasePending
+	}
+
+	pipeline.Status.Steps = statuses
+	pipeline.Status.Phase = phase
+	if observation.started && pipeline.Status.StartTime == nil {
+		pipeline.Status.StartTime = &metav1.Time{Time: time.Now()}
+	}
+	if (phase == controllerapi.PipelinePhaseSucceeded || phase == controllerapi.PipelinePhaseFailed) && !running && pipeline.Status.CompletionTime == nil {
+		pipeline.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	}
+	return observation, nil
+}
+
+// getStepRun returns the InferenceRun of a step, or nil if it does not exist
+func (e *pipelineExternal) getStepRun(ctx context.Context, pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep) (*controllerapi.InferenceRun, error) {
+	iRunUn, err := clientHelper.GetObj(ctx, &finopsdatatypes.ObjectRef{Name: getPipelineChildName(pipeline, step), Namespace: pipeline.Namespace}, controllerapi.GroupVersion.String(), "inferenceruns", e.dynClient)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to retrieve InferenceRun of step %s: %w", step.Name, err)
+	}
+	iRun := &controllerapi.InferenceRun{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(iRunUn.Object, iRun)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert InferenceRun from unstructured: %w", err)
+	}
+	return iRun, nil
+}
+
+// observeStep returns the status of a step from its InferenceRun or Job.
+// The InferenceRuns of failed steps are added to the failed runs of the observation.
+func (e *pipelineExternal) observeStep(ctx context.Context, pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep, observation *pipelineObservation) (controllerapi.PipelineStepStatus, error) {
+	status := controllerapi.PipelineStepStatus{
+		Name:  step.Name,
+		Phase: controllerapi.PipelinePhasePending,
+	}
+	childName := getPipelineChildName(pipeline, step)
+
+	if step.ConfigRef != nil {
+		iRun, err := e.getStepRun(ctx, pipeline, step)
+		if err != nil || iRun == nil {
+			return status, err
+		}
+
+		status.Resource = &v1.ObjectReference{
+			Kind:       controllerapi.Kind,
+			APIVersion: controllerapi.GroupVersion.String(),
+			Namespace:  iRun.Namespace,
+			Name:       iRun.Name,
+			UID:        iRun.UID,
+		}
+		status.Phase = controllerapi.PipelinePhaseRunning
+		status.StartTime = iRun.CreationTimestamp.DeepCopy()
+		if len(iRun.Status.History) == 0 {
+			return status, nil
+		}
+		record := iRun.Status.History[0]
+		switch record.Result {
+		case controllerapi.ExecutionResultSucceeded:
+			status.Phase = controllerapi.PipelinePhaseSucceeded
+			status.CompletionTime = record.CompletionTime
+			status.Message = record.ResultSummary
+		case controllerapi.ExecutionResultFailed:
+			status.Phase = controllerapi.PipelinePhaseFailed
+			status.CompletionTime = record.CompletionTime
+			status.Message = record.FailureReason
+			observation.failedRuns = append(observation.failedRuns, iRun)
+		}
+		return status, nil
+	}
+
+	stepJob, err := e.clientset.BatchV1().Jobs(pipeline.Namespace).Get(ctx, childName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return status, nil
+	} else if err != nil {
+		return status, fmt.Errorf("unable to retrieve Job of step %s: %w", step.Name, err)
+	}
+	status.Resource = &v1.ObjectReference{
+		Kind:       "Job",
+		APIVersion: v1batch.SchemeGroupVersion.String(),
+		Namespace:  stepJob.Namespace,
+		Name:       stepJob.Name,
+		UID:        stepJob.UID,
+	}
+	record, _ := computeExecutionRecord(ctx, e.clientset, stepJob)
+	status.StartTime = record.StartTime
+	status.CompletionTime = record.CompletionTime
+	switch record.Result {
+	case controllerapi.ExecutionResultSucceeded:
+		status.Phase = controllerapi.PipelinePhaseSucceeded
+		status.Message = record.ResultSummary
+	case controllerapi.ExecutionResultFailed:
+		status.Phase = controllerapi.PipelinePhaseFailed
+		status.Message = record.FailureReason
+	default:
+		status.Phase = controllerapi.PipelinePhaseRunning
+	}
+	return status, nil
+}
+
+func getPipelineChildName(pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep) string {
+	return helpers.ComputeJobName(PIPELINE_NAME_PREFIX, pipeline.Name+"-"+step.Name, string(pipeline.UID))
+}
+
+func getStepParameters(pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep) *map[string]string {
+	if pipeline.Spec.Parameters == nil && step.Parameters == nil {
+		return nil
+	}
+	params := map[string]string{}
+	if pipeline.Spec.Parameters != nil {
+		for k, v := range *pipeline.Spec.Parameters {
+			params[k] = v
+		}
+	}
+	if step.Parameters != nil {
+		for k, v := range *step.Parameters {
+			params[k] = v
+		}
+	}
+	return &params
+}
+
+// getStepOutput returns the output storage of a step: its own or, for InferenceConfig steps, the one of the InferenceConfig
+func (e *pipelineExternal) getStepOutput(ctx context.Context, pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep) (controllerapi.StorageMap, error) {
+	if len(step.Output) > 0 {
+		return step.Output, nil
+	}
+	if step.ConfigRef == nil {
+		return nil, fmt.Errorf("step %s has no output storage", step.Name)
+	}
+	iConf, err := getIConfByRef(ctx, getStepConfigRef(pipeline, step), e.dynClient)
+	if err != nil {
+		return nil, err
+	}
+	return iConf.Spec.Storage.Output, nil
+}
+
+func getStepConfigRef(pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep) *finopsdatatypes.ObjectRef {
+	ref := step.ConfigRef.DeepCopy()
+	if ref.Namespace == "" {
+		ref.Namespace = pipeline.Namespace
+	}
+	return ref
+}
+
+// createStepRun creates the InferenceRun of a step, wiring the output of the step in inputFrom to its input
+func (e *pipelineExternal) createStepRun(ctx context.Context, pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep, steps map[string]controllerapi.PipelineStep) error {
+	override := &controllerapi.StorageOverride{
+		Output: step.Output,
+	}
+	if step.InputFrom != "" {
+		input, err := e.getStepOutput(ctx, pipeline, steps[step.InputFrom])
+		if err != nil {
+			return err
+		}
+		override.Input = input
+	}
+	if len(override.Input) == 0 && len(override.Output) == 0 {
+		override = nil
+	}
+
+	iRun := &controllerapi.InferenceRun{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: controllerapi.GroupVersion.String(),
+			Kind:       controllerapi.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPipelineChildName(pipeline, step),
+			Namespace: pipeline.Namespace,
+			Labels: map[string]string{
+				PIPELINE_LABEL:      pipeline.Name,
+				PIPELINE_STEP_LABEL: step.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(pipeline, controllerapi.PipelineGroupVersionKind),
+			},
+		},
+		Spec: controllerapi.InferenceRunSpec{
+			ConfigRef:       getStepConfigRef(pipeline, step),
+			TimeoutSeconds:  step.TimeoutSeconds,
+			Parameters:      getStepParameters(pipeline, step),
+			StorageOverride: override,
+		},
+	}
+	iRunUn, err := clientHelper.ToUnstructured(iRun)
+	if err != nil {
+		return fmt.Errorf("could not convert InferenceRun to unstructured: %w", err)
+	}
+	err = clientHelper.CreateObj(ctx, iRunUn, "inferenceruns", e.dynClient)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// createStepJob creates the Job of a container step with its contract ConfigMap
+func (e *pipelineExternal) createStepJob(ctx context.Context, pipeline *controllerapi.InferencePipeline, step controllerapi.PipelineStep, steps map[string]controllerapi.PipelineStep) error {
+	childName := getPipelineChildName(pipeline, step)
+
+	contract := job.ContractSpec{
+		JobId:      string(pipeline.UID),
+		JobName:    childName,
+		RunName:    pipeline.Name,
+		Namespace:  pipeline.Namespace,
+		Output:     step.Output,
+		Parameters: getStepParameters(pipeline, step),
+	}
+	if step.InputFrom != "" {
+		input, err := e.getStepOutput(ctx, pipeline, steps[step.InputFrom])
+		if err != nil {
+			return err
+		}
+		contract.Input = input
+	}
+	contractJson, err := json.Marshal(contract)
+	if err != nil {
+		return fmt.Errorf("could not marshal contract: %w", err)
+	}
+
+	ownerRef := *metav1.NewControllerRef(pipeline, controllerapi.PipelineGroupVersionKind)
+	configmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            childName,
+			Namespace:       pipeline.Namespace,
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		BinaryData: map[string][]byte{
+			"contract.json": contractJson,
+		},
+	}
+	_, err = e.clientset.CoreV1().ConfigMaps(pipeline.Namespace).Create(ctx, configmap, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("could not create configmap: %w", err)
+	}
+
+	container := step.Container.DeepCopy()
+	if container.Name == "" {
+		container.Name = job.RUNNER_CONTAINER_NAME
+	}
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      "contract",
+		MountPath: job.CONTRACT_MOUNT_PATH,
+	})
+	container.Env = append(container.Env, v1.EnvVar{
+		Name: "pod_uid",
+		ValueFrom: &v1.EnvVarSource{
+			FieldRef: &v1.ObjectFieldSelector{
+				FieldPath: "metadata.uid",
+			},
+		},
+	})
+	if container.TerminationMessagePolicy == "" {
+		container.TerminationMessagePolicy = v1.TerminationMessageFallbackToLogsOnError
+	}
+
+	stepJob := &v1batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      childName,
+			Namespace: pipeline.Namespace,
+			Labels: map[string]string{
+				PIPELINE_LABEL:      pipeline.Name,
+				PIPELINE_STEP_LABEL: step.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: v1batch.JobSpec{
+			Completions: ptr.To(int32(1)),
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{*container},
+					Volumes: []v1.Volume{
+						{
+							Name: "contract",
+							VolumeSource: v1.VolumeSource{
+								ConfigMap: &v1.ConfigMapVolumeSource{
+									LocalObjectReference: v1.LocalObjectReference{
+										Name: childName,
+									},
+								},
+							},
+						},
+					},
+					RestartPolicy:      v1.RestartPolicyNever,
+					ServiceAccountName: os.Getenv("SA_RUNNER"),
+				},
+			},
+		},
+	}
+	if step.TimeoutSeconds != 0 {
+		stepJob.Spec.ActiveDeadlineSeconds = ptr.To(int64(step.TimeoutSeconds))
+	}
+	_, err = e.clientset.BatchV1().Jobs(pipeline.Namespace).Create(ctx, stepJob, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	v1 "k8s.io/api/core/v1"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func configStep(name string, dependsOn ...string) controllerapi.PipelineStep {
+	return controllerapi.PipelineStep{
+		Name:      name,
+		DependsOn: dependsOn,
+		ConfigRef: &finopsdatatypes.ObjectRef{Name: name},
+	}
+}
+
+func TestSortPipelineSteps(t *testing.T) {
+	withInput := func(step controllerapi.PipelineStep, inputFrom string) controllerapi.PipelineStep {
+		step.InputFrom = inputFrom
+		return step
+	}
+
+	tests := map[string]struct {
+		steps   []controllerapi.PipelineStep
+		want    []string
+		wantErr bool
+	}{
+		"independent steps keep their order": {
+			steps: []controllerapi.PipelineStep{configStep("a"), configStep("b")},
+			want:  []string{"a", "b"},
+		},
+		"dependencies first": {
+			steps: []controllerapi.PipelineStep{configStep("c", "b"), configStep("b", "a"), configStep("a")},
+			want:  []string{"a", "b", "c"},
+		},
+		"diamond": {
+			steps: []controllerapi.PipelineStep{configStep("d", "b", "c"), configStep("b", "a"), configStep("c", "a"), configStep("a")},
+			want:  []string{"a", "b", "c", "d"},
+		},
+		"inputFrom implies a dependency": {
+			steps: []controllerapi.PipelineStep{withInput(configStep("b"), "a"), configStep("a")},
+			want:  []string{"a", "b"},
+		},
+		"inputFrom also in dependsOn": {
+			steps: []controllerapi.PipelineStep{withInput(configStep("b", "a"), "a"), configStep("a")},
+			want:  []string{"a", "b"},
+		},
+		"cycle": {
+			steps:   []controllerapi.PipelineStep{configStep("a", "b"), configStep("b", "a")},
+			wantErr: true,
+		},
+		"cycle through inputFrom": {
+			steps:   []controllerapi.PipelineStep{withInput(configStep("a"), "b"), configStep("b", "a")},
+			wantErr: true,
+		},
+		"self dependency": {
+			steps:   []controllerapi.PipelineStep{configStep("a", "a")},
+			wantErr: true,
+		},
+		"unknown dependency": {
+			steps:   []controllerapi.PipelineStep{configStep("a", "missing")},
+			wantErr: true,
+		},
+		"unknown inputFrom": {
+			steps:   []controllerapi.PipelineStep{withInput(configStep("a"), "missing")},
+			wantErr: true,
+		},
+		"duplicate step": {
+			steps:   []controllerapi.PipelineStep{configStep("a"), configStep("a")},
+			wantErr: true,
+		},
+		"both configRef and container": {
+			steps: []controllerapi.PipelineStep{
+				{Name: "a", ConfigRef: &finopsdatatypes.ObjectRef{Name: "a"}, Container: &v1.Container{Image: "runner"}},
+			},
+			wantErr: true,
+		},
+		"neither configRef nor container": {
+			steps:   []controllerapi.PipelineStep{{Name: "a"}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pipeline := &controllerapi.InferencePipeline{
+				Spec: controllerapi.InferencePipelineSpec{Steps: tc.steps},
+			}
+			sorted, err := sortPipelineSteps(pipeline)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", sorted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, step := range sorted {
+				names = append(names, step.Name)
+			}
+			if !reflect.DeepEqual(names, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	controllerapi "kserve-controller/api/v1"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	INFERENCE_CONFIG_LABEL           string = "ai.krateo.io/inference-config"
+	INFERENCE_CONFIG_NAMESPACE_LABEL string = "ai.krateo.io/inference-config-namespace"
+	// Set on the runner service accounts with the namespaces where the controller created their Roles and RoleBindings
+	SECRET_NAMESPACES_ANNOTATION string = "ai.krateo.io/secret-namespaces"
+	// Existing service accounts must carry this label, set to true, to be used by the runners of InferenceConfigs
+	RUNNER_SERVICE_ACCOUNT_LABEL string = "ai.krateo.io/runner-service-account"
+)
+
+// ensureRunnerRBAC creates the service account of the runner pods, with a Role and RoleBinding in the namespace of
+// the InferenceConfig, owned by it. The Role only grants get on the referenced secrets, which must be in the namespace
+// of the InferenceConfig: otherwise its author could read secrets of other namespaces through the runner.
+// The Role is named after the service account and the InferenceConfig so that InferenceConfigs with the same name
+// in different namespaces do not share it.
+// The service accounts, Roles and RoleBindings no longer needed by the InferenceConfig are deleted, including the ones
+// created in other namespaces by previous versions of the controller.
+// Existing service accounts (create false) are only used if labeled with RUNNER_SERVICE_ACCOUNT_LABEL, so that
+// InferenceConfigs cannot run pods with any service account of the namespace, e.g. the one of the controller.
+func ensureRunnerRBAC(ctx context.Context, iConf *controllerapi.InferenceConfig) error {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return reconcileRunnerRBAC(ctx, clientset, iConf)
+}
+
+func reconcileRunnerRBAC(ctx context.Context, clientset kubernetes.Interface, iConf *controllerapi.InferenceConfig) error {
+	saName := ""
+	secretsByNamespace := map[string][]string{}
+	var foreignErr error
+	if iConf.Spec.ServiceAccount != nil && iConf.Spec.ServiceAccount.Create {
+		saName = iConf.GetRunnerServiceAccountName()
+		secrets, err := iConf.GetReferencedSecrets()
+		if err != nil {
+			return fmt.Errorf("could not get secrets referenced by InferenceConfig %s: %w", iConf.Name, err)
+		}
+		for _, secret := range secrets {
+			if secret.Namespace != iConf.Namespace {
+				// The RBAC granted before is removed anyway
+				foreignErr = fmt.Errorf("secret %s/%s is not in the namespace of InferenceConfig %s, the runner service account cannot be granted access to it", secret.Namespace, secret.Name, iConf.Name)
+				secretsByNamespace = map[string][]string{}
+				break
+			}
+			secretsByNamespace[secret.Namespace] = append(secretsByNamespace[secret.Namespace], secret.Name)
+		}
+	}
+	namespaces := make([]string, 0, len(secretsByNamespace))
+	for namespace := range secretsByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+
+	if err := deleteStaleRunnerRBAC(ctx, clientset, iConf, saName, namespaces); err != nil {
+		return err
+	}
+	if foreignErr != nil {
+		return foreignErr
+	}
+	if saName == "" {
+		return checkRunnerServiceAccount(ctx, clientset, iConf)
+	}
+
+	labels := getRunnerRBACLabels(iConf)
+	roleName := getRunnerRoleName(iConf, saName)
+	ownerReferences := []metav1.OwnerReference{
+		*metav1.NewControllerRef(iConf, controllerapi.GroupVersion.WithKind("InferenceConfig")),
+	}
+
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      saName,
+			Namespace: iConf.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				SECRET_NAMESPACES_ANNOTATION: strings.Join(namespaces, ","),
+			},
+			OwnerReferences: ownerReferences,
+		},
+	}
+	saClient := clientset.CoreV1().ServiceAccounts(iConf.Namespace)
+	existing, err := saClient.Get(ctx, saName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := saClient.Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating serviceaccount: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("error getting serviceaccount: %w", err)
+	case !metav1.IsControlledBy(existing, iConf):
+		// Service accounts created by users are not adopted, they would be deleted with the InferenceConfig
+		return fmt.Errorf("serviceaccount %s already exists and is not managed by InferenceConfig %s", saName, iConf.Name)
+	case existing.Annotations[SECRET_NAMESPACES_ANNOTATION] != sa.Annotations[SECRET_NAMESPACES_ANNOTATION]:
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[SECRET_NAMESPACES_ANNOTATION] = sa.Annotations[SECRET_NAMESPACES_ANNOTATION]
+		if _, err := saClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error updating serviceaccount: %w", err)
+		}
+	}
+
+	for _, namespace := range namespaces {
+		names := secretsByNamespace[namespace]
+		slices.Sort(names)
+		objectMeta := metav1.ObjectMeta{
+			Name:            roleName,
+			Namespace:       namespace,
+			Labels:          labels,
+			OwnerReferences: ownerReferences,
+		}
+
+		role := &rbacv1.Role{
+			ObjectMeta: objectMeta,
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups:     []string{""},
+					Resources:     []string{"secrets"},
+					Verbs:         []string{"get"},
+					ResourceNames: slices.Compact(names),
+				},
+			},
+		}
+		if err := ensureRole(ctx, clientset, role); err != nil {
+			return err
+		}
+
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: objectMeta,
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     roleName,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      saName,
+					Namespace: iConf.Namespace,
+				},
+			},
+		}
+		if err := ensureRoleBinding(ctx, clientset, roleBinding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRunnerServiceAccount returns an error if the InferenceConfig uses an existing service account not labeled with
+// RUNNER_SERVICE_ACCOUNT_LABEL
+func checkRunnerServiceAccount(ctx context.Context, clientset kubernetes.Interface, iConf *controllerapi.InferenceConfig) error {
+	saName := iConf.GetRunnerServiceAccountName()
+	if saName == "" {
+		return nil
+	}
+	sa, err := clientset.CoreV1().ServiceAccounts(iConf.Namespace).Get(ctx, saName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting serviceaccount %s: %w", saName, err)
+	}
+	if sa.Labels[RUNNER_SERVICE_ACCOUNT_LABEL] != "true" {
+		return fmt.Errorf("serviceaccount %s is not labeled %s=true and cannot be used by the runners", saName, RUNNER_SERVICE_ACCOUNT_LABEL)
+	}
+	return nil
+}
+
+// ensureRole creates the Role, or updates it when its rules differ
+func ensureRole(ctx context.Context, clientset kubernetes.Interface, role *rbacv1.Role) error {
+	roleClient := clientset.RbacV1().Roles(role.Namespace)
+	existing, err := roleClient.Get(ctx, role.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := roleClient.Create(ctx, role, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating role: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting role: %w", err)
+	}
+	if equality.Semantic.DeepEqual(existing.Rules, role.Rules) && equality.Semantic.DeepEqual(existing.Labels, role.Labels) {
+		return nil
+	}
+	existing.Labels = role.Labels
+	existing.Rules = role.Rules
+	if _, err := roleClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating role: %w", err)
+	}
+	return nil
+}
+
+// ensureRoleBinding creates the RoleBinding, or updates it when its subjects differ. The role of a RoleBinding
+// cannot be changed, so the RoleBinding is re-created when it refers to another Role.
+func ensureRoleBinding(ctx context.Context, clientset kubernetes.Interface, roleBinding *rbacv1.RoleBinding) error {
+	roleBindingClient := clientset.RbacV1().RoleBindings(roleBinding.Namespace)
+	existing, err := roleBindingClient.Get(ctx, roleBinding.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := roleBindingClient.Create(ctx, roleBinding, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating rolebinding: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error getting rolebinding: %w", err)
+	}
+	if existing.RoleRef != roleBinding.RoleRef {
+		if err := roleBindingClient.Delete(ctx, existing.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("error deleting rolebinding: %w", err)
+		}
+		if _, err := roleBindingClient.Create(ctx, roleBinding, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating rolebinding: %w", err)
+		}
+		return nil
+	}
+	if equality.Semantic.DeepEqual(existing.Subjects, roleBinding.Subjects) && equality.Semantic.DeepEqual(existing.Labels, roleBinding.Labels) {
+		return nil
+	}
+	existing.Labels = roleBinding.Labels
+	existing.Subjects = roleBinding.Subjects
+	if _, err := roleBindingClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating rolebinding: %w", err)
+	}
+	return nil
+}
+
+// deleteStaleRunnerRBAC deletes the service accounts created for the InferenceConfig other than saName, and the
+// Roles and RoleBindings of the InferenceConfig other than the ones of saName in namespaces. The Roles and
+// RoleBindings are looked up in the namespaces recorded in the SECRET_NAMESPACES_ANNOTATION of the service accounts,
+// since the controller may not be allowed to list them in all the namespaces.
+func deleteStaleRunnerRBAC(ctx context.Context, clientset kubernetes.Interface, iConf *controllerapi.InferenceConfig, saName string, namespaces []string) error {
+	selector := metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: getRunnerRBACLabels(iConf)})}
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(iConf.Namespace).List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("could not list serviceaccounts of InferenceConfig %s: %w", iConf.Name, err)
+	}
+
+	roleName := ""
+	if saName != "" {
+		roleName = getRunnerRoleName(iConf, saName)
+	}
+	var errs []error
+	for _, sa := range serviceAccounts.Items {
+		if !metav1.IsControlledBy(&sa, iConf) {
+			continue
+		}
+		for _, namespace := range strings.Split(sa.Annotations[SECRET_NAMESPACES_ANNOTATION], ",") {
+			if namespace == "" {
+				continue
+			}
+			keep := ""
+			if slices.Contains(namespaces, namespace) {
+				keep = roleName
+			}
+			errs = append(errs, deleteRunnerRoles(ctx, clientset, namespace, selector, keep))
+		}
+		if sa.Name != saName {
+			err := clientset.CoreV1().ServiceAccounts(sa.Namespace).Delete(ctx, sa.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("could not delete serviceaccount %s: %w", sa.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteRunnerRoles deletes the Roles and RoleBindings matching the selector in the namespace, except the ones named keep
+func deleteRunnerRoles(ctx context.Context, clientset kubernetes.Interface, namespace string, selector metav1.ListOptions, keep string) error {
+	roles, err := clientset.RbacV1().Roles(namespace).List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("could not list roles in namespace %s: %w", namespace, err)
+	}
+	for _, role := range roles.Items {
+		if role.Name == keep {
+			continue
+		}
+		err := clientset.RbacV1().Roles(namespace).Delete(ctx, role.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete role %s in namespace %s: %w", role.Name, namespace, err)
+		}
+	}
+	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(ctx, selector)
+	if err != nil {
+		return fmt.Errorf("could not list rolebindings in namespace %s: %w", namespace, err)
+	}
+	for _, roleBinding := range roleBindings.Items {
+		if roleBinding.Name == keep {
+			continue
+		}
+		err := clientset.RbacV1().RoleBindings(namespace).Delete(ctx, roleBinding.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete rolebinding %s in namespace %s: %w", roleBinding.Name, namespace, err)
+		}
+	}
+	return nil
+}
+
+func getRunnerRBACLabels(iConf *controllerapi.InferenceConfig) map[string]string {
+	return map[string]string{
+		INFERENCE_CONFIG_LABEL:           iConf.Name,
+		INFERENCE_CONFIG_NAMESPACE_LABEL: iConf.Namespace,
+	}
+}
+
+// getRunnerRoleName returns the name of the Role and RoleBinding of the runner service account, with a hash of the
+// namespace and name of the InferenceConfig
+func getRunnerRoleName(iConf *controllerapi.InferenceConfig, saName string) string {
+	sum := sha256.Sum256([]byte(iConf.Namespace + "/" + iConf.Name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+	if len(saName) > 253-len(suffix) {
+		saName = saName[:253-len(suffix)]
+	}
+	return saName + suffix
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/storage"
+)
+
+func testRBACConfig(namespace string, credentialsNamespace string) *controllerapi.InferenceConfig {
+	return &controllerapi.InferenceConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: namespace, UID: types.UID(namespace + "-forecast")},
+		Spec: controllerapi.InferenceConfigSpec{
+			Storage: controllerapi.StorageSpec{
+				Input: controllerapi.StorageMap{
+					storage.KrateoStorage: runtime.RawExtension{Raw: []byte(`{"api":{"path":"/compute/kserveinput","verb":"POST"}}`)},
+				},
+				Output: controllerapi.StorageMap{
+					storage.KrateoStorage: runtime.RawExtension{Raw: []byte(`{"api":{"path":"/compute/kserveoutput","verb":"POST"}}`)},
+				},
+			},
+			CredentialsRef: &finopsdatatypes.ObjectRef{Name: "credentials", Namespace: credentialsNamespace},
+			ServiceAccount: &controllerapi.RunnerServiceAccountSpec{Create: true},
+		},
+	}
+}
+
+func countRoles(t *testing.T, clientset *fake.Clientset, namespace string) (int, int) {
+	t.Helper()
+	roles, err := clientset.RbacV1().Roles(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(roles.Items), len(roleBindings.Items)
+}
+
+func TestReconcileRunnerRBAC(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+
+	// InferenceConfigs with the same name in different namespaces
+	iConfA := testRBACConfig("team-a", "team-a")
+	iConfB := testRBACConfig("team-b", "")
+	for _, iConf := range []*controllerapi.InferenceConfig{iConfA, iConfB} {
+		if err := reconcileRunnerRBAC(ctx, clientset, iConf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, iConf := range []*controllerapi.InferenceConfig{iConfA, iConfB} {
+		if roles, roleBindings := countRoles(t, clientset, iConf.Namespace); roles != 1 || roleBindings != 1 {
+			t.Fatalf("expected 1 role and 1 rolebinding in %s, got %d and %d", iConf.Namespace, roles, roleBindings)
+		}
+		role, err := clientset.RbacV1().Roles(iConf.Namespace).Get(ctx, getRunnerRoleName(iConf, "forecast-runner"), metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !metav1.IsControlledBy(role, iConf) {
+			t.Errorf("role of %s is not owned by the InferenceConfig", iConf.Namespace)
+		}
+		roleBinding, err := clientset.RbacV1().RoleBindings(iConf.Namespace).Get(ctx, role.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if roleBinding.Subjects[0].Namespace != iConf.Namespace || !metav1.IsControlledBy(roleBinding, iConf) {
+			t.Errorf("rolebinding of %s binds %s/%s", iConf.Namespace, roleBinding.Subjects[0].Namespace, roleBinding.Subjects[0].Name)
+		}
+	}
+
+	// Renaming the service account deletes the previous one
+	iConfA.Spec.ServiceAccount.Name = "renamed"
+	if err := reconcileRunnerRBAC(ctx, clientset, iConfA); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts("team-a").Get(ctx, "forecast-runner", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the previous service account to be deleted")
+	}
+	if roles, roleBindings := countRoles(t, clientset, "team-a"); roles != 1 || roleBindings != 1 {
+		t.Errorf("expected 1 role and 1 rolebinding in team-a, got %d and %d", roles, roleBindings)
+	}
+
+	// Disabling create removes everything
+	iConfA.Spec.ServiceAccount.Create = false
+	iConfA.Spec.ServiceAccount.Name = ""
+	if err := reconcileRunnerRBAC(ctx, clientset, iConfA); err != nil {
+		t.Fatal(err)
+	}
+	if roles, roleBindings := countRoles(t, clientset, "team-a"); roles != 0 || roleBindings != 0 {
+		t.Errorf("expected no roles and rolebindings in team-a, got %d and %d", roles, roleBindings)
+	}
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts("team-a").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(serviceAccounts.Items) != 0 {
+		t.Errorf("expected no service accounts in team-a, got %d", len(serviceAccounts.Items))
+	}
+}
+
+func TestReconcileRunnerRBACForeignSecrets(t *testing.T) {
+	ctx := context.Background()
+	iConf := testRBACConfig("team-a", "secrets")
+
+	// Role and RoleBinding created in the namespace of the secret by a previous version of the controller
+	labels := getRunnerRBACLabels(iConf)
+	roleName := getRunnerRoleName(iConf, "forecast-runner")
+	clientset := fake.NewSimpleClientset(
+		&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name:            "forecast-runner",
+			Namespace:       "team-a",
+			Labels:          labels,
+			Annotations:     map[string]string{SECRET_NAMESPACES_ANNOTATION: "secrets"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(iConf, controllerapi.GroupVersion.WithKind("InferenceConfig"))},
+		}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: "secrets", Labels: labels}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: "secrets", Labels: labels}},
+	)
+
+	if err := reconcileRunnerRBAC(ctx, clientset, iConf); err == nil {
+		t.Fatal("expected an error for a secret outside of the namespace of the InferenceConfig")
+	}
+	if roles, roleBindings := countRoles(t, clientset, "secrets"); roles != 0 || roleBindings != 0 {
+		t.Errorf("expected the roles and rolebindings in secrets to be deleted, got %d and %d", roles, roleBindings)
+	}
+}
+
+func TestReconcileRunnerRBACExistingServiceAccount(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		"default service account": {},
+		"labeled service account": {
+			name:   "runner",
+			labels: map[string]string{RUNNER_SERVICE_ACCOUNT_LABEL: "true"},
+		},
+		"service account not labeled": {
+			name:    "runner",
+			wantErr: true,
+		},
+		"missing service account": {
+			name:    "missing",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "runner", Namespace: "team-a", Labels: tc.labels}})
+			iConf := testRBACConfig("team-a", "team-a")
+			iConf.Spec.ServiceAccount = &controllerapi.RunnerServiceAccountSpec{Name: tc.name}
+
+			err := reconcileRunnerRBAC(context.Background(), clientset, iConf)
+			if tc.wantErr && err == nil {
+				t.Error("expected an error")
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcileRunnerRBACDoesNotAdopt(t *testing.T) {
+	existing := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "forecast-runner", Namespace: "team-a"}}
+	clientset := fake.NewSimpleClientset(existing)
+
+	if err := reconcileRunnerRBAC(context.Background(), clientset, testRBACConfig("team-a", "team-a")); err == nil {
+		t.Fatal("expected an error for a service account not created by the controller")
+	}
+	sa, err := clientset.CoreV1().ServiceAccounts("team-a").Get(context.Background(), "forecast-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sa.OwnerReferences) != 0 {
+		t.Errorf("expected the service account to be left untouched, got owner references %v", sa.OwnerReferences)
+	}
+}
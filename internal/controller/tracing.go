@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/tracing"
+)
+
+// The tracer is a no-op until tracing.Setup registers the tracer provider
+var tracer = otel.Tracer("kserve-controller/internal/controller")
+
+// startSpan starts a span of the reconcile in the trace of the InferenceRun
+func startSpan(ctx context.Context, name string, iRun *controllerapi.InferenceRun) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = tracing.ContextWithRun(ctx, iRun.UID)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("inferencerun.name", iRun.Name),
+		attribute.String("inferencerun.namespace", iRun.Namespace),
+	))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
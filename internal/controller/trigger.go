@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	TRIGGER_AT_ANNOTATION string = "ai.krateo.io/trigger-at"
+	// Same annotation set by kubectl create job --from=cronjob
+	CRONJOB_INSTANTIATE_ANNOTATION string = "cronjob.kubernetes.io/instantiate"
+)
+
+// triggerPending returns true if the trigger annotation of a scheduled InferenceRun has not been handled yet.
+// A RFC3339 timestamp in the future delays the trigger until that time, any other value triggers immediately.
+func triggerPending(iRun *controllerapi.InferenceRun) bool {
+	if iRun.Spec.Schedule == nil {
+		return false
+	}
+	triggerAt, ok := iRun.Annotations[TRIGGER_AT_ANNOTATION]
+	if !ok || triggerAt == "" || triggerAt == iRun.Status.LastTriggerAt {
+		return false
+	}
+	if at, err := time.Parse(time.RFC3339, triggerAt); err == nil && at.After(time.Now()) {
+		return false
+	}
+	return true
+}
+
+// getTriggerJobName returns the name of the Job spawned for the trigger, unique for each value of the annotation
+func getTriggerJobName(jobName string, triggerAt string) string {
+	sum := sha256.Sum256([]byte(triggerAt))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	if len(jobName) > 63-len(suffix)-1 {
+		jobName = jobName[:63-len(suffix)-1]
+	}
+	return jobName + "-" + suffix
+}
+
+// triggerJob spawns a one-off Job from the template of the CronJob, with its own contract ConfigMap.
+// The Job is owned by the CronJob, so that it is recorded in the execution history as the scheduled ones.
+func triggerJob(ctx context.Context, cronJob *v1batch.CronJob, iRun *controllerapi.InferenceRun) (string, error) {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return "", fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	triggerJobName := getTriggerJobName(cronJob.Name, iRun.Annotations[TRIGGER_AT_ANNOTATION])
+
+	contract := job.ContractSpec{}
+	err = json.Unmarshal(iRun.Status.Contract, &contract)
+	if err != nil {
+		return "", fmt.Errorf("could not unmarshal contract: %w", err)
+	}
+	contract.JobName = triggerJobName
+	contractJson, err := json.Marshal(contract)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal contract: %w", err)
+	}
+
+	annotations := map[string]string{
+		CRONJOB_INSTANTIATE_ANNOTATION: "manual",
+		TRIGGER_AT_ANNOTATION:          iRun.Annotations[TRIGGER_AT_ANNOTATION],
+	}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	// CronJobs created by previous versions do not set the RUN_LABEL in the job template
+	labels := map[string]string{RUN_LABEL: cronJob.Name}
+	for k, v := range cronJob.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	jobSpec := *cronJob.Spec.JobTemplate.Spec.DeepCopy()
+	for i := range jobSpec.Template.Spec.Volumes {
+		if volume := &jobSpec.Template.Spec.Volumes[i]; volume.Name == "contract" && volume.ConfigMap != nil {
+			volume.ConfigMap.Name = triggerJobName
+		}
+	}
+
+	triggeredJob := &v1batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        triggerJobName,
+			Namespace:   cronJob.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, v1batch.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: jobSpec,
+	}
+	jobClient := clientset.BatchV1().Jobs(cronJob.Namespace)
+	triggeredJob, err = jobClient.Create(ctx, triggeredJob, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// Retry of a previous trigger which failed to create the ConfigMap
+		triggeredJob, err = jobClient.Get(ctx, triggerJobName, metav1.GetOptions{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not create job %s: %w", triggerJobName, err)
+	}
+
+	// The ConfigMap is owned by the Job, to be deleted with it
+	configmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      triggerJobName,
+			Namespace: cronJob.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(triggeredJob, v1batch.SchemeGroupVersion.WithKind("Job")),
+			},
+		},
+		BinaryData: map[string][]byte{
+			"contract.json": contractJson,
+		},
+	}
+	_, err = clientset.CoreV1().ConfigMaps(cronJob.Namespace).Create(ctx, configmap, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("could not create configmap for job %s: %w", triggerJobName, err)
+	}
+	return triggerJobName, nil
+}
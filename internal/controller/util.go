@@ -2,13 +2,22 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
 	"kserve-controller/internal/helpers/kube/client"
 	"os"
 
 	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 
 	"k8s.io/utils/ptr"
@@ -70,30 +79,23 @@ func createJob(jobName string, iRun *controllerapi.InferenceRun, iConf *controll
 	if err != nil {
 		return err
 	}
+	jobSpec, specHash, err := getDesiredJobSpec(jobName, iRun, iConf, iRun.Status.Contract)
+	if err != nil {
+		return err
+	}
 	jobClient := clientset.BatchV1().Jobs(iRun.Namespace)
 	job := &v1batch.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: jobName,
+			Name:   jobName,
+			Labels: map[string]string{RUN_LABEL: jobName},
+			Annotations: map[string]string{
+				SPEC_HASH_ANNOTATION: specHash,
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(iRun, controllerapi.GroupVersion.WithKind("InferenceRun")),
 			},
 		},
-		Spec: getJobSpec(jobName, iConf),
-	}
-	if iRun.Spec.TimeoutSeconds != 0 {
-		job.Spec.ActiveDeadlineSeconds = ptr.To(int64(iRun.Spec.TimeoutSeconds))
-	}
-	if iConf.Spec.CredentialsRef != nil {
-		job.Spec.Template.Spec.ImagePullSecrets = []v1.LocalObjectReference{
-			{
-				Name: iConf.Spec.CredentialsRef.Name,
-			},
-		}
-	}
-	if iConf.Spec.AutoDeletePolicy != nil {
-		if *iConf.Spec.AutoDeletePolicy != controllerapi.AutoDeletePolicyNone {
-			job.Spec.TTLSecondsAfterFinished = ptr.To(int32(300))
-		}
+		Spec: jobSpec,
 	}
 	_, err = jobClient.Create(context.TODO(), job, metav1.CreateOptions{})
 	if err != nil {
@@ -111,41 +113,244 @@ func createCronJob(jobName string, iRun *controllerapi.InferenceRun, iConf *cont
 	if err != nil {
 		return err
 	}
+	jobSpec, specHash, err := getDesiredJobSpec(jobName, iRun, iConf, iRun.Status.Contract)
+	if err != nil {
+		return err
+	}
 	jobClient := clientset.BatchV1().CronJobs(iRun.Namespace)
 	job := &v1batch.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: jobName,
+			Annotations: map[string]string{
+				SPEC_HASH_ANNOTATION: specHash,
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(iRun, controllerapi.GroupVersion.WithKind("InferenceRun")),
 			},
 		},
 		Spec: v1batch.CronJobSpec{
-			Schedule: *iRun.Spec.Schedule,
 			JobTemplate: v1batch.JobTemplateSpec{
-				Spec: getJobSpec(jobName, iConf),
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{RUN_LABEL: jobName},
+				},
+				Spec: jobSpec,
 			},
 		},
 	}
+	applyScheduling(&job.Spec, iRun)
+	_, err = jobClient.Create(context.TODO(), job, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getDesiredJobSpec returns the spec of the runner Job and the hash of its inputs and of the contract,
+// which is stored in the SPEC_HASH_ANNOTATION to detect when the Job or CronJob must be updated
+func getDesiredJobSpec(jobName string, iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig, contract []byte) (v1batch.JobSpec, string, error) {
+	jobSpec, err := getJobSpec(jobName, iConf)
+	if err != nil {
+		return jobSpec, "", err
+	}
+	for i := range jobSpec.Template.Spec.Containers {
+		if container := &jobSpec.Template.Spec.Containers[i]; container.Name == job.RUNNER_CONTAINER_NAME {
+			container.Env = append(container.Env, getParameterEnv(iRun)...)
+		}
+	}
+	if iRun.Spec.Sharding != nil {
+		jobSpec.CompletionMode = ptr.To(v1batch.IndexedCompletion)
+		jobSpec.Completions = ptr.To(iRun.Spec.Sharding.Shards)
+		jobSpec.Parallelism = ptr.To(iRun.Spec.Sharding.GetParallelism())
+	}
 	if iRun.Spec.TimeoutSeconds != 0 {
-		job.Spec.JobTemplate.Spec.ActiveDeadlineSeconds = ptr.To(int64(iRun.Spec.TimeoutSeconds))
+		jobSpec.ActiveDeadlineSeconds = ptr.To(int64(iRun.Spec.TimeoutSeconds))
 	}
-	if iConf.Spec.CredentialsRef != nil {
-		job.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets = []v1.LocalObjectReference{
-			{
-				Name: iConf.Spec.CredentialsRef.Name,
+	if ttl := iRun.GetCleanupPolicy(iConf).JobTTLSecondsAfterFinished; ttl != nil {
+		jobSpec.TTLSecondsAfterFinished = ptr.To(*ttl)
+	} else if iConf.GetAutoDeletePolicy() != controllerapi.AutoDeletePolicyNone {
+		jobSpec.TTLSecondsAfterFinished = ptr.To(int32(300))
+	}
+
+	// Only the inputs of the InferenceConfig and of the InferenceRun are hashed, not the rendered spec, so that changes
+	// to the template of the controller, e.g. after an upgrade, do not update the CronJobs nor recreate the Jobs
+	hashedContract, err := getHashedContract(contract)
+	if err != nil {
+		return jobSpec, "", err
+	}
+	toHash, err := json.Marshal(struct {
+		Image                   string                          `json:"image"`
+		CredentialsRef          *finopsdatatypes.ObjectRef      `json:"credentialsRef,omitempty"`
+		PodTemplate             *v1.PodTemplateSpec             `json:"podTemplate,omitempty"`
+		ServiceAccount          string                          `json:"serviceAccount,omitempty"`
+		Env                     []v1.EnvVar                     `json:"env,omitempty"`
+		EnvFrom                 []v1.EnvFromSource              `json:"envFrom,omitempty"`
+		SecretMounts            []controllerapi.SecretMount     `json:"secretMounts,omitempty"`
+		ParametersFrom          []controllerapi.ParameterSource `json:"parametersFrom,omitempty"`
+		Sharding                *controllerapi.ShardingSpec     `json:"sharding,omitempty"`
+		TimeoutSeconds          int                             `json:"timeoutSeconds,omitempty"`
+		TTLSecondsAfterFinished *int32                          `json:"ttlSecondsAfterFinished,omitempty"`
+		Contract                []byte                          `json:"contract"`
+	}{
+		Image:                   iConf.Spec.Image,
+		CredentialsRef:          iConf.Spec.CredentialsRef,
+		PodTemplate:             iConf.Spec.PodTemplate,
+		ServiceAccount:          iConf.GetRunnerServiceAccountName(),
+		Env:                     iConf.Spec.Env,
+		EnvFrom:                 iConf.Spec.EnvFrom,
+		SecretMounts:            iConf.Spec.SecretMounts,
+		ParametersFrom:          iRun.Spec.ParametersFrom,
+		Sharding:                iRun.Spec.Sharding,
+		TimeoutSeconds:          iRun.Spec.TimeoutSeconds,
+		TTLSecondsAfterFinished: jobSpec.TTLSecondsAfterFinished,
+		Contract:                hashedContract,
+	})
+	if err != nil {
+		return jobSpec, "", fmt.Errorf("could not marshal job spec for hashing: %w", err)
+	}
+	sum := sha256.Sum256(toHash)
+	return jobSpec, hex.EncodeToString(sum[:])[:16], nil
+}
+
+// getHashedContract returns the contract without its trace context, which changes when tracing is enabled or
+// disabled in the controller and must not update the CronJobs nor recreate the Jobs
+func getHashedContract(contract []byte) ([]byte, error) {
+	if len(contract) == 0 {
+		return contract, nil
+	}
+	spec := job.ContractSpec{}
+	if err := json.Unmarshal(contract, &spec); err != nil {
+		return nil, fmt.Errorf("could not unmarshal contract for hashing: %w", err)
+	}
+	if spec.TraceContext == nil {
+		return contract, nil
+	}
+	spec.TraceContext = nil
+	return json.Marshal(spec)
+}
+
+func getCronJob(name string, iRun *controllerapi.InferenceRun) (*v1batch.CronJob, error) {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return nil, fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.BatchV1().CronJobs(iRun.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// applyScheduling sets the schedule and the scheduling controls of the InferenceRun on the CronJob spec.
+// Unset controls are set to the Kubernetes defaults, so that the result can be compared with the live CronJob.
+func applyScheduling(spec *v1batch.CronJobSpec, iRun *controllerapi.InferenceRun) {
+	spec.Schedule = *iRun.Spec.Schedule
+	spec.TimeZone = nil
+	spec.ConcurrencyPolicy = v1batch.AllowConcurrent
+	spec.StartingDeadlineSeconds = nil
+	spec.Suspend = ptr.To(false)
+	spec.SuccessfulJobsHistoryLimit = ptr.To(int32(3))
+	spec.FailedJobsHistoryLimit = ptr.To(int32(1))
+
+	scheduling := iRun.Spec.Scheduling
+	if scheduling == nil {
+		return
+	}
+	if scheduling.TimeZone != nil {
+		spec.TimeZone = ptr.To(*scheduling.TimeZone)
+	}
+	if scheduling.ConcurrencyPolicy != "" {
+		spec.ConcurrencyPolicy = scheduling.ConcurrencyPolicy
+	}
+	if scheduling.StartingDeadlineSeconds != nil {
+		spec.StartingDeadlineSeconds = ptr.To(*scheduling.StartingDeadlineSeconds)
+	}
+	if scheduling.Suspend != nil {
+		spec.Suspend = ptr.To(*scheduling.Suspend)
+	}
+	if scheduling.SuccessfulJobsHistoryLimit != nil {
+		spec.SuccessfulJobsHistoryLimit = ptr.To(*scheduling.SuccessfulJobsHistoryLimit)
+	}
+	if scheduling.FailedJobsHistoryLimit != nil {
+		spec.FailedJobsHistoryLimit = ptr.To(*scheduling.FailedJobsHistoryLimit)
+	}
+}
+
+// cronJobUpToDate checks the scheduling controls and, unless the update policy is Never, the spec hash of the CronJob
+func cronJobUpToDate(cronJob *v1batch.CronJob, specHash string, iRun *controllerapi.InferenceRun) bool {
+	desired := cronJob.Spec.DeepCopy()
+	applyScheduling(desired, iRun)
+	if !equality.Semantic.DeepEqual(*desired, cronJob.Spec) {
+		return false
+	}
+	return iRun.Spec.GetUpdatePolicy() == controllerapi.UpdatePolicyNever || !specHashOutdated(cronJob.Annotations, specHash)
+}
+
+// specHashOutdated returns true if the annotations have a spec hash different from specHash. Objects created before
+// the hash was introduced have no hash: they are considered up to date and annotated by annotateSpecHash.
+func specHashOutdated(annotations map[string]string, specHash string) bool {
+	hash := annotations[SPEC_HASH_ANNOTATION]
+	return hash != "" && hash != specHash
+}
+
+// annotateSpecHash sets the spec hash on a Job or CronJob without one, without changing its spec. CronJobs without a
+// hash were created by previous versions, which did not set the RUN_LABEL on the job template either.
+func annotateSpecHash(ctx context.Context, resource string, name string, specHash string, iRun *controllerapi.InferenceRun) error {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	patchObj := map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{SPEC_HASH_ANNOTATION: specHash},
+		},
+	}
+	if resource == "cronjobs" {
+		patchObj["spec"] = map[string]any{
+			"jobTemplate": map[string]any{
+				"metadata": map[string]any{
+					"labels": map[string]string{RUN_LABEL: name},
+				},
 			},
 		}
 	}
-	if iConf.Spec.AutoDeletePolicy != nil {
-		if *iConf.Spec.AutoDeletePolicy != controllerapi.AutoDeletePolicyNone {
-			job.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = ptr.To(int32(300))
-		}
+	patch, err := json.Marshal(patchObj)
+	if err != nil {
+		return err
 	}
-	_, err = jobClient.Create(context.TODO(), job, metav1.CreateOptions{})
+	if resource == "cronjobs" {
+		_, err = clientset.BatchV1().CronJobs(iRun.Namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = clientset.BatchV1().Jobs(iRun.Namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// updateCronJob updates in place the job template and the scheduling controls of the CronJob
+func updateCronJob(ctx context.Context, cronJob *v1batch.CronJob, jobSpec v1batch.JobSpec, specHash string, iRun *controllerapi.InferenceRun) error {
+	config := ctrl.GetConfigOrDie()
+	if config == nil {
+		return fmt.Errorf("could not get rest config")
+	}
+	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return err
 	}
-	return nil
+	if cronJob.Annotations == nil {
+		cronJob.Annotations = map[string]string{}
+	}
+	cronJob.Annotations[SPEC_HASH_ANNOTATION] = specHash
+	if cronJob.Spec.JobTemplate.Labels == nil {
+		cronJob.Spec.JobTemplate.Labels = map[string]string{}
+	}
+	cronJob.Spec.JobTemplate.Labels[RUN_LABEL] = cronJob.Name
+	cronJob.Spec.JobTemplate.Spec = jobSpec
+	applyScheduling(&cronJob.Spec, iRun)
+	_, err = clientset.BatchV1().CronJobs(iRun.Namespace).Update(ctx, cronJob, metav1.UpdateOptions{})
+	return err
 }
 
 func updateStatus(ctx context.Context, iRun *controllerapi.InferenceRun) error {
@@ -256,20 +461,22 @@ func deleteRun(ctx context.Context, iRun *controllerapi.InferenceRun) error {
 	return nil
 }
 
-func getJobSpec(jobName string, iConf *controllerapi.InferenceConfig) v1batch.JobSpec {
-	return v1batch.JobSpec{
+func getJobSpec(jobName string, iConf *controllerapi.InferenceConfig) (v1batch.JobSpec, error) {
+	jobSpec := v1batch.JobSpec{
 		Completions: ptr.To(int32(1)),
 		Template: v1.PodTemplateSpec{
 			Spec: v1.PodSpec{
 				Containers: []v1.Container{
 					{
-						Name:            "inference",
+						Name:            job.RUNNER_CONTAINER_NAME,
 						ImagePullPolicy: v1.PullAlways,
 						Image:           iConf.Spec.Image,
+						// The termination message is reported as result summary in the execution history
+						TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
 						VolumeMounts: []v1.VolumeMount{
 							{
 								Name:      "contract",
-								MountPath: "/tmp",
+								MountPath: job.CONTRACT_MOUNT_PATH,
 							},
 						},
 						Env: []v1.EnvVar{
@@ -301,4 +508,97 @@ func getJobSpec(jobName string, iConf *controllerapi.InferenceConfig) v1batch.Jo
 			},
 		},
 	}
+	if saName := iConf.GetRunnerServiceAccountName(); saName != "" {
+		jobSpec.Template.Spec.ServiceAccountName = saName
+	}
+
+	container := &jobSpec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, iConf.Spec.Env...)
+	container.EnvFrom = append(container.EnvFrom, iConf.Spec.EnvFrom...)
+	for i, mount := range iConf.Spec.SecretMounts {
+		if job.IsContractMountPath(mount.MountPath) {
+			return v1batch.JobSpec{}, fmt.Errorf("secret %s cannot be mounted on the contract directory %s", mount.SecretName, job.CONTRACT_MOUNT_PATH)
+		}
+		volumeName := fmt.Sprintf("secret-%d", i)
+		jobSpec.Template.Spec.Volumes = append(jobSpec.Template.Spec.Volumes, v1.Volume{
+			Name: volumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: mount.SecretName,
+					Items:      mount.Items,
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      volumeName,
+			MountPath: mount.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	if iConf.Spec.CredentialsRef != nil {
+		jobSpec.Template.Spec.ImagePullSecrets = []v1.LocalObjectReference{
+			{
+				Name: iConf.Spec.CredentialsRef.Name,
+			},
+		}
+	}
+
+	if iConf.Spec.PodTemplate != nil {
+		if err := job.ValidatePodTemplate(iConf.Spec.PodTemplate, field.NewPath("spec", "podTemplate")).ToAggregate(); err != nil {
+			return v1batch.JobSpec{}, fmt.Errorf("invalid podTemplate of InferenceConfig %s: %w", iConf.Name, err)
+		}
+		template, err := applyPodTemplate(jobSpec.Template, iConf.Spec.PodTemplate)
+		if err != nil {
+			return v1batch.JobSpec{}, fmt.Errorf("unable to apply podTemplate of InferenceConfig %s: %w", iConf.Name, err)
+		}
+		jobSpec.Template = template
+	}
+	return jobSpec, nil
+}
+
+// applyPodTemplate applies the overrides as a strategic merge patch on the pod template
+func applyPodTemplate(template v1.PodTemplateSpec, overrides *v1.PodTemplateSpec) (v1.PodTemplateSpec, error) {
+	original, err := json.Marshal(template)
+	if err != nil {
+		return template, fmt.Errorf("could not marshal pod template: %w", err)
+	}
+
+	patchObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(overrides)
+	if err != nil {
+		return template, fmt.Errorf("could not convert pod template overrides to unstructured: %w", err)
+	}
+	// null values in a strategic merge patch delete the field, but here they are only unset fields of the overrides
+	removeNulls(patchObj)
+	patch, err := json.Marshal(patchObj)
+	if err != nil {
+		return template, fmt.Errorf("could not marshal pod template overrides: %w", err)
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, v1.PodTemplateSpec{})
+	if err != nil {
+		return template, fmt.Errorf("could not merge pod template overrides: %w", err)
+	}
+
+	result := v1.PodTemplateSpec{}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return template, fmt.Errorf("could not unmarshal merged pod template: %w", err)
+	}
+	return result, nil
+}
+
+func removeNulls(obj map[string]any) {
+	for k, v := range obj {
+		switch val := v.(type) {
+		case nil:
+			delete(obj, k)
+		case map[string]any:
+			removeNulls(val)
+		case []any:
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					removeNulls(m)
+				}
+			}
+		}
+	}
 }
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+)
+
+func TestApplyPodTemplate(t *testing.T) {
+	base := func() v1.PodTemplateSpec {
+		return v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "runner"}},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "runner",
+				RestartPolicy:      v1.RestartPolicyNever,
+				Containers: []v1.Container{{
+					Name:         job.RUNNER_CONTAINER_NAME,
+					Image:        "runner:latest",
+					Env:          []v1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+					VolumeMounts: []v1.VolumeMount{{Name: "contract", MountPath: job.CONTRACT_MOUNT_PATH}},
+				}},
+				Volumes: []v1.Volume{{Name: "contract"}},
+			},
+		}
+	}
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+		Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+
+	tests := map[string]struct {
+		overrides *v1.PodTemplateSpec
+		want      func() v1.PodTemplateSpec
+	}{
+		"resources merged into the runner container by name": {
+			overrides: &v1.PodTemplateSpec{
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: job.RUNNER_CONTAINER_NAME, Resources: resources}}},
+			},
+			want: func() v1.PodTemplateSpec {
+				template := base()
+				template.Spec.Containers[0].Resources = resources
+				return template
+			},
+		},
+		"unset fields of the overrides do not delete the fields of the template": {
+			overrides: &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"team": "finops"}},
+			},
+			want: func() v1.PodTemplateSpec {
+				template := base()
+				template.Annotations = map[string]string{"team": "finops"}
+				return template
+			},
+		},
+		"labels and scheduling constraints merged": {
+			overrides: &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "finops"}},
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+					Tolerations:  []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "inference"}},
+				},
+			},
+			want: func() v1.PodTemplateSpec {
+				template := base()
+				template.Labels["team"] = "finops"
+				template.Spec.NodeSelector = map[string]string{"kubernetes.io/arch": "amd64"}
+				template.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "inference"}}
+				return template
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := applyPodTemplate(base(), tc.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tc.want(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestGetDesiredJobSpecHash(t *testing.T) {
+	iRun := &controllerapi.InferenceRun{ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: "finops"}}
+	iConf := &controllerapi.InferenceConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: "finops"},
+		Spec:       controllerapi.InferenceConfigSpec{Image: "runner:latest"},
+	}
+	hash := func(contract job.ContractSpec) string {
+		contractJson, err := json.Marshal(contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, specHash, err := getDesiredJobSpec("inf-forecast-1234", iRun, iConf, contractJson)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return specHash
+	}
+	contract := job.ContractSpec{JobId: "1234", RunName: "forecast", Namespace: "finops"}
+
+	tests := map[string]struct {
+		change  func(contract *job.ContractSpec)
+		changed bool
+	}{
+		"trace context set": {
+			change: func(contract *job.ContractSpec) {
+				contract.TraceContext = map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+			},
+		},
+		"parameters changed": {
+			change: func(contract *job.ContractSpec) {
+				contract.Parameters = &map[string]string{"table": "costs"}
+			},
+			changed: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			changed := contract
+			tc.change(&changed)
+			if got := hash(changed) != hash(contract); got != tc.changed {
+				t.Errorf("expected the spec hash to change: %t, got %t", tc.changed, got)
+			}
+		})
+	}
+}
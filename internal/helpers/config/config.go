@@ -11,10 +11,15 @@ type Configuration struct {
 	WatchNamespace   string
 	PollingInterval  string
 	MaxReconcileRate string
+	EnableWebhooks   bool
+	// Timeout set by the defaulting webhook on InferenceRuns without timeoutSeconds, 0 for no timeout
+	DefaultTimeoutSeconds int
+	// Export the spans of the reconciles through OTLP and pass the trace context to the runners
+	EnableTracing bool
 }
 
 func (r *Configuration) String() string {
-	return fmt.Sprintf("WATCH_NAMESPACE: %s - MAX_RECONCILE_RATE: %s - POLLING_INTERVAL: %s", r.WatchNamespace, r.MaxReconcileRate, r.PollingInterval)
+	return fmt.Sprintf("WATCH_NAMESPACE: %s - MAX_RECONCILE_RATE: %s - POLLING_INTERVAL: %s - ENABLE_WEBHOOKS: %t - DEFAULT_TIMEOUT_SECONDS: %d - ENABLE_TRACING: %t", r.WatchNamespace, r.MaxReconcileRate, r.PollingInterval, r.EnableWebhooks, r.DefaultTimeoutSeconds, r.EnableTracing)
 }
 
 func ParseConfig() Configuration {
@@ -24,12 +29,21 @@ func ParseConfig() Configuration {
 		env.String("MAX_RECONCILE_RATE", "1"), "Maximum reconcile rate (default: 1)")
 	pollingInterval := flag.String("pollinginterval",
 		env.String("POLLING_INTERVAL", "300"), "Polling interval in seconds (default: 300)")
+	enableWebhooks := flag.Bool("enablewebhooks",
+		env.Bool("ENABLE_WEBHOOKS", false), "Serve the admission webhooks, requires a TLS certificate (default: false)")
+	defaultTimeoutSeconds := flag.Int("defaulttimeoutseconds",
+		env.Int("DEFAULT_TIMEOUT_SECONDS", 0), "Timeout of the InferenceRuns without timeoutSeconds (default: 0, no timeout)")
+	enableTracing := flag.Bool("enabletracing",
+		env.Bool("ENABLE_TRACING", false), "Export traces through OTLP, configured with the OTEL_EXPORTER_OTLP_* variables (default: false)")
 
 	flag.Parse()
 
 	return Configuration{
-		WatchNamespace:   *watchNamespace,
-		PollingInterval:  *pollingInterval,
-		MaxReconcileRate: *maxReconcileRate,
+		WatchNamespace:        *watchNamespace,
+		PollingInterval:       *pollingInterval,
+		MaxReconcileRate:      *maxReconcileRate,
+		EnableWebhooks:        *enableWebhooks,
+		DefaultTimeoutSeconds: *defaultTimeoutSeconds,
+		EnableTracing:         *enableTracing,
 	}
 }
@@ -1,15 +1,82 @@
 package job
 
-import controllerapi "kserve-controller/api/v1"
+import (
+	controllerapi "kserve-controller/api/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // This file defines the contract structure for KServe inference jobs launched by InferenceRun resources.
 // Note: the jobs themselves do not run the inference. Kserve jobs will run the inference.
 // These jobs only retrieve input data, call KServe endpoints, and store the results.
 type ContractSpec struct {
-	JobId      string                   `json:"jobId,omitempty"`
-	JobName    string                   `json:"jobName,omitempty"`
-	KServe     controllerapi.KServeSpec `json:"kserve"`
-	Input      controllerapi.StorageMap `json:"input,omitempty"`
-	Output     controllerapi.StorageMap `json:"output,omitempty"`
-	Parameters *map[string]string       `json:"parameters,omitempty"`
+	JobId        string                     `json:"jobId,omitempty"`
+	JobName      string                     `json:"jobName,omitempty"`
+	RunName      string                     `json:"runName,omitempty"`
+	Namespace    string                     `json:"namespace,omitempty"`
+	KServe       controllerapi.KServeSpec   `json:"kserve"`
+	Input        controllerapi.StorageMap   `json:"input,omitempty"`
+	Output       controllerapi.StorageMap   `json:"output,omitempty"`
+	OutputFormat controllerapi.OutputFormat `json:"outputFormat,omitempty"`
+	Parameters   *map[string]string         `json:"parameters,omitempty"`
+	// Templates of the parameters rendered by the runner at each execution of a CronJob
+	ParameterTemplates map[string]string `json:"parameterTemplates,omitempty"`
+	// Environment variables of the parameters set from Secrets, by parameter name
+	SecretParameters map[string]string `json:"secretParameters,omitempty"`
+	Secrets          *ContractSecrets  `json:"secrets,omitempty"`
+	// Logical time of the execution and time window of the data to process, only set for backfill Jobs.
+	// Runners use the current time when they are not set.
+	ExecutionTime *metav1.Time `json:"executionTime,omitempty"`
+	WindowStart   *metav1.Time `json:"windowStart,omitempty"`
+	WindowEnd     *metav1.Time `json:"windowEnd,omitempty"`
+	// Number of shards of Indexed Jobs, the index of the shard is in the JOB_COMPLETION_INDEX environment variable
+	ShardCount int32 `json:"shardCount,omitempty"`
+	// W3C trace context (traceparent) of the trace of the InferenceRun, only set when tracing is enabled.
+	// Runners start their spans as children of it and forward it to KServe.
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+}
+
+// ContractSecrets tells the runner where to find the secrets and environment injected in its container.
+// It only contains names and never the values, so that credentials do not end up in the contract.
+type ContractSecrets struct {
+	// Names of the environment variables set from the env of the InferenceConfig
+	Env []string `json:"env,omitempty"`
+	// Secrets and ConfigMaps exposed as environment variables
+	EnvFrom []ContractEnvFrom `json:"envFrom,omitempty"`
+	// Secrets mounted as volumes
+	Mounts []ContractSecretMount `json:"mounts,omitempty"`
+}
+
+type ContractEnvFrom struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+type ContractSecretMount struct {
+	SecretName string `json:"secretName"`
+	MountPath  string `json:"mountPath"`
+}
+
+func ComputeContractSecrets(iConf *controllerapi.InferenceConfig) *ContractSecrets {
+	if len(iConf.Spec.Env) == 0 && len(iConf.Spec.EnvFrom) == 0 && len(iConf.Spec.SecretMounts) == 0 {
+		return nil
+	}
+
+	secrets := &ContractSecrets{}
+	for _, env := range iConf.Spec.Env {
+		secrets.Env = append(secrets.Env, env.Name)
+	}
+	for _, envFrom := range iConf.Spec.EnvFrom {
+		if envFrom.SecretRef != nil {
+			secrets.EnvFrom = append(secrets.EnvFrom, ContractEnvFrom{Kind: "Secret", Name: envFrom.SecretRef.Name, Prefix: envFrom.Prefix})
+		}
+		if envFrom.ConfigMapRef != nil {
+			secrets.EnvFrom = append(secrets.EnvFrom, ContractEnvFrom{Kind: "ConfigMap", Name: envFrom.ConfigMapRef.Name, Prefix: envFrom.Prefix})
+		}
+	}
+	for _, mount := range iConf.Spec.SecretMounts {
+		secrets.Mounts = append(secrets.Mounts, ContractSecretMount{SecretName: mount.SecretName, MountPath: mount.MountPath})
+	}
+	return secrets
 }
@@ -0,0 +1,11 @@
+package job
+
+import "path"
+
+// CONTRACT_MOUNT_PATH is the directory where the contract ConfigMap is mounted in the runner containers
+const CONTRACT_MOUNT_PATH string = "/tmp"
+
+// IsContractMountPath returns true if mountPath is the directory of the contract, which cannot be used by other volumes
+func IsContractMountPath(mountPath string) bool {
+	return path.Clean(mountPath) == CONTRACT_MOUNT_PATH
+}
@@ -0,0 +1,86 @@
+package job
+
+import (
+	"maps"
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// RUNNER_CONTAINER_NAME is the name of the runner container in the Jobs of the controller
+const RUNNER_CONTAINER_NAME string = "inference"
+
+// Fields of the pod template that the podTemplate of InferenceConfigs can set. The other fields, such as the service
+// account, the image, the command, the security context or the volumes, are only set by the controller.
+var (
+	podTemplateFields  = []string{"metadata", "spec"}
+	podMetadataFields  = []string{"labels", "annotations"}
+	podSpecFields      = []string{"nodeSelector", "tolerations", "affinity", "containers"}
+	podContainerFields = []string{"name", "resources"}
+)
+
+const podTemplateForbidden string = "the podTemplate can only set labels, annotations, nodeSelector, tolerations, affinity and the resources of the " + RUNNER_CONTAINER_NAME + " container"
+
+// ValidatePodTemplate rejects the overrides of the pod template of the runner Jobs outside of the labels, the
+// annotations, the scheduling constraints and the resources of the runner container
+func ValidatePodTemplate(template *v1.PodTemplateSpec, fldPath *field.Path) field.ErrorList {
+	if template == nil {
+		return nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	allErrs := forbidFields(obj, podTemplateFields, fldPath)
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		allErrs = append(allErrs, forbidFields(metadata, podMetadataFields, fldPath.Child("metadata"))...)
+	}
+	spec, ok := obj["spec"].(map[string]any)
+	if !ok {
+		return allErrs
+	}
+	allErrs = append(allErrs, forbidFields(spec, podSpecFields, fldPath.Child("spec"))...)
+	containers, _ := spec["containers"].([]any)
+	for i, item := range containers {
+		containerPath := fldPath.Child("spec", "containers").Index(i)
+		container, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name := container["name"]; name != RUNNER_CONTAINER_NAME {
+			allErrs = append(allErrs, field.NotSupported(containerPath.Child("name"), name, []string{RUNNER_CONTAINER_NAME}))
+		}
+		allErrs = append(allErrs, forbidFields(container, podContainerFields, containerPath)...)
+	}
+	return allErrs
+}
+
+// forbidFields returns an error for each field of obj that is set and is not in allowed
+func forbidFields(obj map[string]any, allowed []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, key := range slices.Sorted(maps.Keys(obj)) {
+		if isUnset(obj[key]) || slices.Contains(allowed, key) {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child(key), podTemplateForbidden))
+	}
+	return allErrs
+}
+
+// isUnset returns true for the zero values left by the conversion of the unset fields of the pod template
+func isUnset(value any) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(val) == 0
+	case []any:
+		return len(val) == 0
+	case string:
+		return val == ""
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidatePodTemplate(t *testing.T) {
+	tests := map[string]struct {
+		template *v1.PodTemplateSpec
+		fields   []string
+	}{
+		"no overrides": {},
+		"allowed fields": {
+			template: &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "finops"}, Annotations: map[string]string{"owner": "finops"}},
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+					Tolerations:  []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+					Affinity:     &v1.Affinity{NodeAffinity: &v1.NodeAffinity{}},
+					Containers: []v1.Container{{
+						Name:      RUNNER_CONTAINER_NAME,
+						Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")}},
+					}},
+				},
+			},
+		},
+		"service account": {
+			template: &v1.PodTemplateSpec{Spec: v1.PodSpec{ServiceAccountName: "admin"}},
+			fields:   []string{"spec.podTemplate.spec.serviceAccountName"},
+		},
+		"host network and host path volume": {
+			template: &v1.PodTemplateSpec{Spec: v1.PodSpec{
+				HostNetwork: true,
+				Volumes:     []v1.Volume{{Name: "root", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/"}}}},
+			}},
+			fields: []string{"spec.podTemplate.spec.hostNetwork", "spec.podTemplate.spec.volumes"},
+		},
+		"image, command and privileged security context of the runner container": {
+			template: &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:            RUNNER_CONTAINER_NAME,
+				Image:           "attacker:latest",
+				Command:         []string{"sh"},
+				SecurityContext: &v1.SecurityContext{Privileged: ptr.To(true)},
+			}}}},
+			fields: []string{
+				"spec.podTemplate.spec.containers[0].command",
+				"spec.podTemplate.spec.containers[0].image",
+				"spec.podTemplate.spec.containers[0].securityContext",
+			},
+		},
+		"other container": {
+			template: &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "sidecar"}}}},
+			fields:   []string{"spec.podTemplate.spec.containers[0].name"},
+		},
+		"metadata other than labels and annotations": {
+			template: &v1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Name: "runner"}},
+			fields:   []string{"spec.podTemplate.metadata.name"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := ValidatePodTemplate(tc.template, field.NewPath("spec", "podTemplate"))
+			got := []string{}
+			for _, err := range errs {
+				got = append(got, err.Field)
+			}
+			if len(got) != len(tc.fields) {
+				t.Fatalf("expected errors on %v, got %v", tc.fields, errs)
+			}
+			for i := range tc.fields {
+				if got[i] != tc.fields[i] {
+					t.Errorf("expected errors on %v, got %v", tc.fields, errs)
+				}
+			}
+		})
+	}
+}
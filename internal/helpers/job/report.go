@@ -0,0 +1,33 @@
+package job
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RunnerReport is the termination message written by runners built with the runner SDK: a short summary of the
+// execution and the metrics measured by the runner
+type RunnerReport struct {
+	Summary string         `json:"summary"`
+	Metrics *RunnerMetrics `json:"metrics,omitempty"`
+}
+
+type RunnerMetrics struct {
+	InputFetchSeconds  float64   `json:"inputFetchSeconds,omitempty"`
+	OutputWriteSeconds float64   `json:"outputWriteSeconds,omitempty"`
+	InferenceSeconds   []float64 `json:"inferenceSeconds,omitempty"`
+	InputRows          int       `json:"inputRows,omitempty"`
+	Predictions        int       `json:"predictions,omitempty"`
+	InputBytes         int       `json:"inputBytes,omitempty"`
+	OutputBytes        int       `json:"outputBytes,omitempty"`
+}
+
+// ParseTerminationMessage returns the report of the runner. Termination messages that are not a report, written by
+// runners not built with the SDK or by generic containers, are returned as summary.
+func ParseTerminationMessage(message string) RunnerReport {
+	report := RunnerReport{}
+	if !strings.HasPrefix(strings.TrimSpace(message), "{") || json.Unmarshal([]byte(message), &report) != nil {
+		return RunnerReport{Summary: message}
+	}
+	return report
+}
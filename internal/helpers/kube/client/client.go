@@ -102,3 +102,20 @@ func ToUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
 	}
 	return &unstructured.Unstructured{Object: data}, nil
 }
+
+func ListObj(ctx context.Context, namespace string, ApiVersion string, Resource string, dynClient *dynamic.DynamicClient) (*unstructured.UnstructuredList, error) {
+	gv, err := schema.ParseGroupVersion(ApiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse GroupVersion from ApiVersion: %w", err)
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    gv.Group,
+		Version:  gv.Version,
+		Resource: Resource,
+	}
+	res, err := dynClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list resources %s in namespace %s, with apiVersion %s: %w", Resource, namespace, ApiVersion, err)
+	}
+	return res, nil
+}
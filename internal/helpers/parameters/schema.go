@@ -0,0 +1,111 @@
+package parameters
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+// ValidationError lists the parameters that do not match the schema of the InferenceConfig
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid parameters: " + strings.Join(e.Errors, "; ")
+}
+
+// Defaults returns the default values of the parameters of the schema that are not set
+func Defaults(schema []controllerapi.ParameterSpec, isSet func(name string) bool) map[string]string {
+	defaults := map[string]string{}
+	for _, spec := range schema {
+		if spec.Default != nil && !isSet(spec.Name) {
+			defaults[spec.Name] = *spec.Default
+		}
+	}
+	return defaults
+}
+
+// ValidateSchema checks the values of the parameters against the schema.
+// Values are checked by type, enum and pattern, unresolved parameters (templates rendered by the runner and Secret
+// parameters) are only checked to be declared. An empty schema accepts any parameter.
+func ValidateSchema(schema []controllerapi.ParameterSpec, values map[string]string, unresolved []string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	specs := map[string]controllerapi.ParameterSpec{}
+	for _, spec := range schema {
+		specs[spec.Name] = spec
+	}
+
+	errs := []string{}
+	names := make([]string, 0, len(values)+len(unresolved))
+	for name := range values {
+		names = append(names, name)
+	}
+	names = append(names, unresolved...)
+	sort.Strings(names)
+	for _, name := range names {
+		spec, ok := specs[name]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("parameter %s is not declared by the InferenceConfig", name))
+			continue
+		}
+		value, resolved := values[name]
+		if !resolved {
+			continue
+		}
+		if err := ValidateValue(spec, value); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for _, spec := range schema {
+		_, set := values[spec.Name]
+		if spec.Required && !set && !slices.Contains(unresolved, spec.Name) {
+			errs = append(errs, fmt.Sprintf("parameter %s is required", spec.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateValue checks a value against the type, the enum and the pattern of the parameter
+func ValidateValue(spec controllerapi.ParameterSpec, value string) error {
+	var err error
+	switch spec.Type {
+	case controllerapi.ParameterTypeInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case controllerapi.ParameterTypeNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case controllerapi.ParameterTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("parameter %s must be of type %s, got %q", spec.Name, spec.Type, value)
+	}
+
+	if len(spec.Enum) > 0 && !slices.Contains(spec.Enum, value) {
+		return fmt.Errorf("parameter %s must be one of %s, got %q", spec.Name, strings.Join(spec.Enum, ", "), value)
+	}
+
+	if spec.Pattern != "" {
+		re, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return fmt.Errorf("parameter %s has an invalid pattern: %w", spec.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("parameter %s must match %s, got %q", spec.Name, spec.Pattern, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package parameters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+var testSchema = []controllerapi.ParameterSpec{
+	{Name: "table", Type: controllerapi.ParameterTypeString, Required: true},
+	{Name: "length", Type: controllerapi.ParameterTypeInteger, Default: ptr.To("512")},
+	{Name: "threshold", Type: controllerapi.ParameterTypeNumber},
+	{Name: "dry_run", Type: controllerapi.ParameterTypeBoolean},
+	{Name: "region", Type: controllerapi.ParameterTypeString, Enum: []string{"eu", "us"}},
+	{Name: "day", Type: controllerapi.ParameterTypeString, Pattern: `^\d{4}-\d{2}-\d{2}$`, Default: ptr.To(`{{ .ScheduledTime | date "2006-01-02" }}`)},
+}
+
+func TestDefaults(t *testing.T) {
+	tests := map[string]struct {
+		set  []string
+		want map[string]string
+	}{
+		"nothing set": {
+			want: map[string]string{"length": "512", "day": `{{ .ScheduledTime | date "2006-01-02" }}`},
+		},
+		"defaults overridden": {
+			set:  []string{"length", "day"},
+			want: map[string]string{},
+		},
+		"parameters without default": {
+			set:  []string{"table", "region"},
+			want: map[string]string{"length": "512", "day": `{{ .ScheduledTime | date "2006-01-02" }}`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Defaults(testSchema, func(name string) bool {
+				for _, set := range tc.set {
+					if set == name {
+						return true
+					}
+				}
+				return false
+			})
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := map[string]struct {
+		schema     []controllerapi.ParameterSpec
+		values     map[string]string
+		unresolved []string
+		errors     int
+	}{
+		"valid values": {
+			schema: testSchema,
+			values: map[string]string{"table": "costs", "length": "64", "threshold": "0.5", "dry_run": "true", "region": "eu", "day": "2026-03-01"},
+		},
+		"empty schema accepts any parameter": {
+			values: map[string]string{"anything": "value"},
+		},
+		"undeclared parameter": {
+			schema: testSchema,
+			values: map[string]string{"table": "costs", "other": "value"},
+			errors: 1,
+		},
+		"undeclared unresolved parameter": {
+			schema:     testSchema,
+			values:     map[string]string{"table": "costs"},
+			unresolved: []string{"token"},
+			errors:     1,
+		},
+		"missing required parameter": {
+			schema: testSchema,
+			values: map[string]string{"length": "64"},
+			errors: 1,
+		},
+		"required parameter unresolved": {
+			schema:     testSchema,
+			unresolved: []string{"table"},
+		},
+		"unresolved values are not checked": {
+			schema:     testSchema,
+			values:     map[string]string{"table": "costs"},
+			unresolved: []string{"length", "day"},
+		},
+		"invalid values": {
+			schema: testSchema,
+			values: map[string]string{"table": "costs", "length": "long", "threshold": "high", "dry_run": "maybe", "region": "asia", "day": "yesterday"},
+			errors: 5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateSchema(tc.schema, tc.values, tc.unresolved)
+			if tc.errors == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a ValidationError, got %v", err)
+			}
+			if len(validationErr.Errors) != tc.errors {
+				t.Errorf("expected %d errors, got %v", tc.errors, validationErr.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := map[string]struct {
+		spec    controllerapi.ParameterSpec
+		value   string
+		wantErr bool
+	}{
+		"string":                {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeString}, value: "anything"},
+		"untyped":               {spec: controllerapi.ParameterSpec{Name: "p"}, value: "anything"},
+		"integer":               {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeInteger}, value: "-12"},
+		"integer with decimals": {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeInteger}, value: "1.5", wantErr: true},
+		"number":                {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeNumber}, value: "1.5e3"},
+		"not a number":          {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeNumber}, value: "one", wantErr: true},
+		"boolean":               {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeBoolean}, value: "false"},
+		"not a boolean":         {spec: controllerapi.ParameterSpec{Name: "p", Type: controllerapi.ParameterTypeBoolean}, value: "yes", wantErr: true},
+		"in enum":               {spec: controllerapi.ParameterSpec{Name: "p", Enum: []string{"a", "b"}}, value: "b"},
+		"not in enum":           {spec: controllerapi.ParameterSpec{Name: "p", Enum: []string{"a", "b"}}, value: "c", wantErr: true},
+		"matching pattern":      {spec: controllerapi.ParameterSpec{Name: "p", Pattern: "^[a-z_]+$"}, value: "cost_table"},
+		"not matching pattern":  {spec: controllerapi.ParameterSpec{Name: "p", Pattern: "^[a-z_]+$"}, value: "Costs", wantErr: true},
+		"invalid pattern":       {spec: controllerapi.ParameterSpec{Name: "p", Pattern: "("}, value: "a", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateValue(tc.spec, tc.value)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected an error for %q", tc.value)
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
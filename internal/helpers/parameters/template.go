@@ -0,0 +1,61 @@
+package parameters
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData are the variables available in the templates of the parameters
+type TemplateData struct {
+	// Logical time of the execution: creation time of one-shot runs, end of the window for backfill Jobs
+	ScheduledTime time.Time
+	RunName       string
+	Namespace     string
+	JobId         string
+	JobName       string
+}
+
+var funcs = template.FuncMap{
+	// {{ .ScheduledTime | date "2006-01-02" }}
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	// {{ .ScheduledTime | add "-1h" }}
+	"add": func(duration string, t time.Time) (time.Time, error) {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return t, err
+		}
+		return t.Add(d), nil
+	},
+	// {{ .ScheduledTime | addDays -1 }}
+	"addDays": func(days int, t time.Time) time.Time {
+		return t.AddDate(0, 0, days)
+	},
+}
+
+// IsTemplate returns true if the value of the parameter must be rendered
+func IsTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// Validate renders the template of a parameter with sample data, to catch errors before the runner renders it
+func Validate(name string, value string) error {
+	_, err := Render(name, value, TemplateData{ScheduledTime: time.Now()})
+	return err
+}
+
+// Render renders the template of a parameter
+func Render(name string, value string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid template for parameter %s: %w", name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("could not render parameter %s: %w", name, err)
+	}
+	return out.String(), nil
+}
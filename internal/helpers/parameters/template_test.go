@@ -0,0 +1,97 @@
+package parameters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	data := TemplateData{
+		ScheduledTime: time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC),
+		RunName:       "forecast",
+		Namespace:     "finops",
+		JobId:         "1234",
+		JobName:       "inf-forecast-1234",
+	}
+
+	tests := map[string]struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		"plain value": {
+			value: "azuretoolkit",
+			want:  "azuretoolkit",
+		},
+		"variables": {
+			value: "{{ .Namespace }}/{{ .RunName }}/{{ .JobName }}/{{ .JobId }}",
+			want:  "finops/forecast/inf-forecast-1234/1234",
+		},
+		"date": {
+			value: `{{ .ScheduledTime | date "2006-01-02" }}`,
+			want:  "2026-03-01",
+		},
+		"addDays across the month": {
+			value: `{{ .ScheduledTime | addDays -1 | date "2006-01-02" }}`,
+			want:  "2026-02-28",
+		},
+		"add": {
+			value: `{{ .ScheduledTime | add "-3h" | date "2006-01-02T15:04" }}`,
+			want:  "2026-02-28T23:30",
+		},
+		"invalid duration": {
+			value:   `{{ .ScheduledTime | add "yesterday" }}`,
+			wantErr: true,
+		},
+		"unknown variable": {
+			value:   "{{ .Table }}",
+			wantErr: true,
+		},
+		"unknown function": {
+			value:   "{{ .ScheduledTime | format }}",
+			wantErr: true,
+		},
+		"unterminated action": {
+			value:   "{{ .RunName ",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Render("param", tc.value, data)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIsTemplate(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  bool
+	}{
+		"plain value":  {value: "azuretoolkit", want: false},
+		"braces":       {value: "{not a template}", want: false},
+		"template":     {value: "{{ .RunName }}", want: true},
+		"invalid open": {value: "{{ .RunName", want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsTemplate(tc.value); got != tc.want {
+				t.Errorf("expected %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
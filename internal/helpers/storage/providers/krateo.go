@@ -4,8 +4,12 @@ package providers
 
 import (
 	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+
+	"kserve-runner-sdk/mapping"
 )
 
 type KrateoStorage struct {
 	Api finopsdatatypes.API `json:"api"`
+	// The mapping and its defaults are defined in the runner SDK, which applies them
+	Mapping *mapping.KrateoMapping `json:"mapping,omitempty"`
 }
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const SERVICE_NAME string = "kserve-controller"
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup exports the spans through OTLP over HTTP. The exporter is configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT=http://otel-collector:4318.
+// The returned function flushes the spans, it must be called before the process exits.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(SERVICE_NAME)),
+		resource.WithFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// ContextWithRun returns a context whose parent span is the root of the trace of the InferenceRun.
+// The trace ID is the UID of the InferenceRun, so that the spans of all its reconciles and of its runners belong
+// to the same trace and the trace context in the contract does not change between reconciles.
+func ContextWithRun(ctx context.Context, uid types.UID) context.Context {
+	spanContext := runSpanContext(uid)
+	if !spanContext.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, spanContext)
+}
+
+// TraceContext returns the W3C trace context headers (traceparent) of the trace of the InferenceRun, passed to the
+// runner in the contract
+func TraceContext(uid types.UID) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ContextWithRun(context.Background(), uid), carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+func runSpanContext(uid types.UID) trace.SpanContext {
+	traceID, err := trace.TraceIDFromHex(strings.ReplaceAll(string(uid), "-", ""))
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	sum := sha256.Sum256([]byte(uid))
+	spanID, err := trace.SpanIDFromHex(hex.EncodeToString(sum[:8]))
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+}
@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/job"
+	"kserve-controller/internal/helpers/parameters"
+	"kserve-controller/internal/helpers/storage"
+)
+
+// +kubebuilder:webhook:path=/mutate-ai-krateo-io-v1-inferenceconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=ai.krateo.io,resources=inferenceconfigs,verbs=create;update,versions=v1,name=minferenceconfig.ai.krateo.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-ai-krateo-io-v1-inferenceconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=ai.krateo.io,resources=inferenceconfigs,verbs=create;update,versions=v1,name=vinferenceconfig.ai.krateo.io,admissionReviewVersions=v1
+
+func SetupInferenceConfigWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &controllerapi.InferenceConfig{}).
+		WithDefaulter(&InferenceConfigDefaulter{}).
+		WithValidator(&InferenceConfigValidator{}).
+		Complete()
+}
+
+// InferenceConfigDefaulter sets the defaults of the fields read by the controller without a nil check
+type InferenceConfigDefaulter struct{}
+
+func (d *InferenceConfigDefaulter) Default(ctx context.Context, iConf *controllerapi.InferenceConfig) error {
+	iConf.Default()
+	return nil
+}
+
+// InferenceConfigValidator rejects InferenceConfigs that would only fail when the runner Jobs are created
+type InferenceConfigValidator struct{}
+
+func (v *InferenceConfigValidator) ValidateCreate(ctx context.Context, iConf *controllerapi.InferenceConfig) (admission.Warnings, error) {
+	return nil, validateInferenceConfig(iConf)
+}
+
+func (v *InferenceConfigValidator) ValidateUpdate(ctx context.Context, oldIConf, iConf *controllerapi.InferenceConfig) (admission.Warnings, error) {
+	return nil, validateInferenceConfig(iConf)
+}
+
+func (v *InferenceConfigValidator) ValidateDelete(ctx context.Context, iConf *controllerapi.InferenceConfig) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateInferenceConfig(iConf *controllerapi.InferenceConfig) error {
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateStorageMap(iConf.Spec.Storage.Input, specPath.Child("storage", "input"))...)
+	allErrs = append(allErrs, validateStorageMap(iConf.Spec.Storage.Output, specPath.Child("storage", "output"))...)
+
+	for i, mount := range iConf.Spec.SecretMounts {
+		if job.IsContractMountPath(mount.MountPath) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("secretMounts").Index(i).Child("mountPath"), mount.MountPath, "cannot be the directory of the contract"))
+		}
+	}
+
+	allErrs = append(allErrs, job.ValidatePodTemplate(iConf.Spec.PodTemplate, specPath.Child("podTemplate"))...)
+
+	// The controller grants the service accounts it creates access only to the secrets in the namespace of the
+	// InferenceConfig
+	if iConf.Spec.ServiceAccount != nil && iConf.Spec.ServiceAccount.Create {
+		foreign, err := iConf.GetForeignSecrets()
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("storage"), iConf.Spec.Storage, err.Error()))
+		}
+		for _, secret := range foreign {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("serviceAccount", "create"),
+				fmt.Sprintf("secret %s/%s is not in the namespace of the InferenceConfig", secret.Namespace, secret.Name)))
+		}
+	}
+
+	for i, spec := range iConf.Spec.Parameters {
+		allErrs = append(allErrs, validateParameterSpec(spec, specPath.Child("parameters").Index(i))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(controllerapi.GroupVersion.WithKind("InferenceConfig").GroupKind(), iConf.Name, allErrs)
+}
+
+// validateStorageMap rejects the storage labels without a storage provider
+func validateStorageMap(storageMap controllerapi.StorageMap, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	supported := storage.GetStorageSpecs()
+	supportedValues := make([]string, 0, len(supported))
+	for _, label := range supported {
+		supportedValues = append(supportedValues, string(label))
+	}
+	for label := range storageMap {
+		if !slices.Contains(supported, label) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key(string(label)), string(label), supportedValues))
+		}
+	}
+	return allErrs
+}
+
+// validateParameterSpec checks that the pattern compiles and that the default and the enum values are valid values
+func validateParameterSpec(spec controllerapi.ParameterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Pattern != "" {
+		if _, err := regexp.Compile(spec.Pattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pattern"), spec.Pattern, err.Error()))
+			return allErrs
+		}
+	}
+	for i, value := range spec.Enum {
+		enumSpec := spec
+		enumSpec.Enum = nil
+		if err := parameters.ValidateValue(enumSpec, value); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("enum").Index(i), value, err.Error()))
+		}
+	}
+	if spec.Default != nil {
+		if parameters.IsTemplate(*spec.Default) {
+			if err := parameters.Validate(spec.Name, *spec.Default); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("default"), *spec.Default, err.Error()))
+			}
+		} else if err := parameters.ValidateValue(spec, *spec.Default); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("default"), *spec.Default, err.Error()))
+		}
+	}
+	return allErrs
+}
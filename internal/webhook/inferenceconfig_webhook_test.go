@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"testing"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func TestValidateParameterSpec(t *testing.T) {
+	tests := map[string]struct {
+		spec   controllerapi.ParameterSpec
+		fields []string
+	}{
+		"valid spec": {
+			spec: controllerapi.ParameterSpec{Name: "length", Type: controllerapi.ParameterTypeInteger, Enum: []string{"64", "512"}, Default: ptr.To("512")},
+		},
+		"template default": {
+			spec: controllerapi.ParameterSpec{Name: "day", Pattern: `^\d{4}-\d{2}-\d{2}$`, Default: ptr.To(`{{ .ScheduledTime | date "2006-01-02" }}`)},
+		},
+		"invalid template default": {
+			spec:   controllerapi.ParameterSpec{Name: "day", Default: ptr.To(`{{ .ScheduledTime | format }}`)},
+			fields: []string{"spec.parameters[0].default"},
+		},
+		"default of the wrong type": {
+			spec:   controllerapi.ParameterSpec{Name: "length", Type: controllerapi.ParameterTypeInteger, Default: ptr.To("long")},
+			fields: []string{"spec.parameters[0].default"},
+		},
+		"default not in enum": {
+			spec:   controllerapi.ParameterSpec{Name: "region", Enum: []string{"eu", "us"}, Default: ptr.To("asia")},
+			fields: []string{"spec.parameters[0].default"},
+		},
+		"enum value of the wrong type": {
+			spec:   controllerapi.ParameterSpec{Name: "length", Type: controllerapi.ParameterTypeInteger, Enum: []string{"64", "long"}},
+			fields: []string{"spec.parameters[0].enum[1]"},
+		},
+		"invalid pattern": {
+			spec:   controllerapi.ParameterSpec{Name: "day", Pattern: "(", Default: ptr.To("2026-03-01")},
+			fields: []string{"spec.parameters[0].pattern"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := validateParameterSpec(tc.spec, field.NewPath("spec").Child("parameters").Index(0))
+			assertFieldErrors(t, errs, tc.fields)
+		})
+	}
+}
+
+func TestValidateInferenceConfigSecretMounts(t *testing.T) {
+	tests := map[string]struct {
+		mountPath string
+		fields    []string
+	}{
+		"other directory":               {mountPath: "/etc/secrets"},
+		"contract directory":            {mountPath: "/tmp", fields: []string{"spec.secretMounts[0].mountPath"}},
+		"contract directory not clean":  {mountPath: "/tmp/", fields: []string{"spec.secretMounts[0].mountPath"}},
+		"subdirectory of the contract":  {mountPath: "/tmp/secrets"},
+		"contract directory with parts": {mountPath: "/var/../tmp", fields: []string{"spec.secretMounts[0].mountPath"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iConf := &controllerapi.InferenceConfig{
+				Spec: controllerapi.InferenceConfigSpec{
+					SecretMounts: []controllerapi.SecretMount{{SecretName: "tokens", MountPath: tc.mountPath}},
+				},
+			}
+			err := validateInferenceConfig(iConf)
+			if len(tc.fields) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error on %v", tc.fields)
+			}
+		})
+	}
+}
+
+func assertFieldErrors(t *testing.T, errs field.ErrorList, fields []string) {
+	t.Helper()
+	got := []string{}
+	for _, err := range errs {
+		got = append(got, err.Field)
+	}
+	if len(got) != len(fields) {
+		t.Fatalf("expected errors on %v, got %v", fields, errs)
+	}
+	for i := range fields {
+		if got[i] != fields[i] {
+			t.Errorf("expected errors on %v, got %v", fields, errs)
+		}
+	}
+}
+
+func TestValidateInferenceConfigForeignSecrets(t *testing.T) {
+	tests := map[string]struct {
+		credentialsNamespace string
+		create               bool
+		wantErr              bool
+	}{
+		"secret in the namespace":            {credentialsNamespace: "team-a", create: true},
+		"secret without namespace":           {credentialsNamespace: "", create: true},
+		"secret in another namespace":        {credentialsNamespace: "secrets", create: true, wantErr: true},
+		"default service account of runners": {credentialsNamespace: "secrets"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iConf := &controllerapi.InferenceConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "forecast", Namespace: "team-a"},
+				Spec: controllerapi.InferenceConfigSpec{
+					CredentialsRef: &finopsdatatypes.ObjectRef{Name: "credentials", Namespace: tc.credentialsNamespace},
+					ServiceAccount: &controllerapi.RunnerServiceAccountSpec{Create: tc.create},
+				},
+			}
+			err := validateInferenceConfig(iConf)
+			if tc.wantErr && err == nil {
+				t.Error("expected an error")
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	controllerapi "kserve-controller/api/v1"
+	"kserve-controller/internal/helpers/config"
+	"kserve-controller/internal/helpers/parameters"
+)
+
+// +kubebuilder:webhook:path=/mutate-ai-krateo-io-v1-inferencerun,mutating=true,failurePolicy=fail,sideEffects=None,groups=ai.krateo.io,resources=inferenceruns,verbs=create;update,versions=v1,name=minferencerun.ai.krateo.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-ai-krateo-io-v1-inferencerun,mutating=false,failurePolicy=fail,sideEffects=None,groups=ai.krateo.io,resources=inferenceruns,verbs=create;update,versions=v1,name=vinferencerun.ai.krateo.io,admissionReviewVersions=v1
+
+func SetupInferenceRunWebhookWithManager(mgr ctrl.Manager, config config.Configuration) error {
+	return ctrl.NewWebhookManagedBy(mgr, &controllerapi.InferenceRun{}).
+		WithDefaulter(&InferenceRunDefaulter{
+			defaultTimeoutSeconds: config.DefaultTimeoutSeconds,
+		}).
+		WithValidator(&InferenceRunValidator{
+			// The referenced InferenceConfig can be outside of the namespaces cached by the manager
+			reader: mgr.GetAPIReader(),
+		}).
+		Complete()
+}
+
+// InferenceRunDefaulter sets the namespace of the referenced InferenceConfig and the timeout from the configuration
+// of the controller
+type InferenceRunDefaulter struct {
+	defaultTimeoutSeconds int
+}
+
+func (d *InferenceRunDefaulter) Default(ctx context.Context, iRun *controllerapi.InferenceRun) error {
+	iRun.Default(d.defaultTimeoutSeconds)
+	return nil
+}
+
+// InferenceRunValidator rejects InferenceRuns that would only fail at reconcile time, including the ones
+// referencing a missing InferenceConfig
+type InferenceRunValidator struct {
+	reader client.Reader
+}
+
+func (v *InferenceRunValidator) ValidateCreate(ctx context.Context, iRun *controllerapi.InferenceRun) (admission.Warnings, error) {
+	return nil, v.validateInferenceRun(ctx, iRun)
+}
+
+func (v *InferenceRunValidator) ValidateUpdate(ctx context.Context, oldIRun, iRun *controllerapi.InferenceRun) (admission.Warnings, error) {
+	// Metadata updates, e.g. of finalizers and annotations, are allowed even if the InferenceConfig is gone
+	if equality.Semantic.DeepEqual(oldIRun.Spec, iRun.Spec) {
+		return nil, nil
+	}
+	return nil, v.validateInferenceRun(ctx, iRun)
+}
+
+func (v *InferenceRunValidator) ValidateDelete(ctx context.Context, iRun *controllerapi.InferenceRun) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *InferenceRunValidator) validateInferenceRun(ctx context.Context, iRun *controllerapi.InferenceRun) error {
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	if iRun.Spec.TimeoutSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("timeoutSeconds"), iRun.Spec.TimeoutSeconds, "must be greater than or equal to 0"))
+	}
+
+	if iRun.Spec.Schedule != nil {
+		allErrs = append(allErrs, validateSchedule(*iRun.Spec.Schedule, specPath.Child("schedule"))...)
+	}
+	if iRun.Spec.Scheduling != nil && iRun.Spec.Scheduling.TimeZone != nil {
+		if _, err := time.LoadLocation(*iRun.Spec.Scheduling.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("scheduling", "timeZone"), *iRun.Spec.Scheduling.TimeZone, "unknown time zone"))
+		}
+	}
+
+	if iRun.Spec.Parameters != nil {
+		for name, value := range *iRun.Spec.Parameters {
+			if !parameters.IsTemplate(value) {
+				continue
+			}
+			if err := parameters.Validate(name, value); err != nil {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("parameters").Key(name), value, err.Error()))
+			}
+		}
+	}
+	for i, source := range iRun.Spec.ParametersFrom {
+		if (source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("parametersFrom").Index(i), source.Name, "must have exactly one of configMapKeyRef and secretKeyRef"))
+		}
+	}
+
+	if iRun.Spec.Backfill != nil {
+		backfillPath := specPath.Child("backfill")
+		if !iRun.Spec.Backfill.End.After(iRun.Spec.Backfill.Start.Time) {
+			allErrs = append(allErrs, field.Invalid(backfillPath.Child("end"), iRun.Spec.Backfill.End, "must be after start"))
+		}
+		if iRun.Spec.Backfill.Interval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(backfillPath.Child("interval"), iRun.Spec.Backfill.Interval.Duration.String(), "must be greater than 0"))
+		}
+	}
+	if iRun.Spec.Matrix != nil && (iRun.Spec.Schedule != nil || iRun.Spec.Backfill != nil) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("matrix"), "matrix is not supported with schedule and backfill"))
+	}
+	if iRun.Spec.Matrix != nil {
+		for i, source := range iRun.Spec.Matrix.ParametersFrom {
+			sourcePath := specPath.Child("matrix", "parametersFrom").Index(i)
+			if source.SecretKeyRef != nil {
+				allErrs = append(allErrs, field.Forbidden(sourcePath.Child("secretKeyRef"), "matrix values are copied in the contract of the Jobs and cannot be read from Secrets"))
+			} else if source.ConfigMapKeyRef == nil {
+				allErrs = append(allErrs, field.Required(sourcePath.Child("configMapKeyRef"), "the ConfigMap key of the values must be set"))
+			}
+		}
+	}
+
+	if iRun.Spec.StorageOverride != nil {
+		overridePath := specPath.Child("storageOverride")
+		allErrs = append(allErrs, validateStorageMap(iRun.Spec.StorageOverride.Input, overridePath.Child("input"))...)
+		allErrs = append(allErrs, validateStorageMap(iRun.Spec.StorageOverride.Output, overridePath.Child("output"))...)
+	}
+
+	if iRun.Spec.ConfigRef == nil || iRun.Spec.ConfigRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("configRef"), "the InferenceConfig of the run must be set"))
+	} else {
+		allErrs = append(allErrs, v.validateConfigRef(ctx, iRun, specPath.Child("configRef"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(controllerapi.GroupVersion.WithKind(controllerapi.Kind).GroupKind(), iRun.Name, allErrs)
+}
+
+// validateSchedule parses the schedule as the CronJob controller does
+func validateSchedule(schedule string, fldPath *field.Path) field.ErrorList {
+	if strings.Contains(schedule, "TZ") {
+		return field.ErrorList{field.Invalid(fldPath, schedule, "cannot use TZ or CRON_TZ in schedule, use scheduling.timeZone instead")}
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, schedule, err.Error())}
+	}
+	return nil
+}
+
+// validateConfigRef checks that the referenced InferenceConfig exists and is compatible with the run
+func (v *InferenceRunValidator) validateConfigRef(ctx context.Context, iRun *controllerapi.InferenceRun, fldPath *field.Path) field.ErrorList {
+	namespace := iRun.Spec.ConfigRef.Namespace
+	if namespace == "" {
+		namespace = iRun.Namespace
+	}
+
+	iConf := &controllerapi.InferenceConfig{}
+	err := v.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: iRun.Spec.ConfigRef.Name}, iConf)
+	if apierrors.IsNotFound(err) {
+		return field.ErrorList{field.NotFound(fldPath, fmt.Sprintf("%s/%s", namespace, iRun.Spec.ConfigRef.Name))}
+	} else if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("unable to retrieve InferenceConfig: %w", err))}
+	}
+
+	if iRun.Spec.Schedule != nil && iConf.Spec.AutoDeletePolicy != nil && *iConf.Spec.AutoDeletePolicy != controllerapi.AutoDeletePolicyNone {
+		return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("InferenceConfig %s has autoDeletePolicy %s, which is not supported with schedule", iConf.Name, *iConf.Spec.AutoDeletePolicy))}
+	}
+	return validateRunParameters(iRun, iConf)
+}
+
+// validateRunParameters checks the parameters of the InferenceRun against the schema of the InferenceConfig.
+// Templates and values read from ConfigMaps and Secrets are only checked to be declared, the controller validates
+// them once resolved.
+func validateRunParameters(iRun *controllerapi.InferenceRun, iConf *controllerapi.InferenceConfig) field.ErrorList {
+	if len(iConf.Spec.Parameters) == 0 {
+		return nil
+	}
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	values := map[string]string{}
+	unresolved := []string{}
+	if iRun.Spec.Parameters != nil {
+		for name, value := range *iRun.Spec.Parameters {
+			if parameters.IsTemplate(value) {
+				unresolved = append(unresolved, name)
+			} else {
+				values[name] = value
+			}
+		}
+	}
+	for _, source := range iRun.Spec.ParametersFrom {
+		unresolved = append(unresolved, source.Name)
+	}
+	specs := map[string]controllerapi.ParameterSpec{}
+	for _, spec := range iConf.Spec.Parameters {
+		specs[spec.Name] = spec
+		if _, ok := values[spec.Name]; !ok && spec.Default != nil {
+			unresolved = append(unresolved, spec.Name)
+		}
+	}
+	if iRun.Spec.Matrix != nil {
+		for name, list := range iRun.Spec.Matrix.Parameters {
+			unresolved = append(unresolved, name)
+			spec, ok := specs[name]
+			if !ok {
+				continue
+			}
+			for i, value := range list {
+				if err := parameters.ValidateValue(spec, value); err != nil {
+					allErrs = append(allErrs, field.Invalid(specPath.Child("matrix", "parameters").Key(name).Index(i), value, err.Error()))
+				}
+			}
+		}
+		for _, source := range iRun.Spec.Matrix.ParametersFrom {
+			unresolved = append(unresolved, source.Name)
+		}
+	}
+
+	var paramErr *parameters.ValidationError
+	if errors.As(parameters.ValidateSchema(iConf.Spec.Parameters, values, unresolved), &paramErr) {
+		for _, err := range paramErr.Errors {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("parameters"), iRun.Spec.Parameters, err))
+		}
+	}
+	return allErrs
+}
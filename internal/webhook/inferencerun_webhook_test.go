@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	controllerapi "kserve-controller/api/v1"
+)
+
+func TestValidateRunParameters(t *testing.T) {
+	iConf := &controllerapi.InferenceConfig{
+		Spec: controllerapi.InferenceConfigSpec{
+			Parameters: []controllerapi.ParameterSpec{
+				{Name: "table", Required: true},
+				{Name: "length", Type: controllerapi.ParameterTypeInteger, Default: ptr.To("512")},
+				{Name: "region", Enum: []string{"eu", "us"}},
+				{Name: "token"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		spec   controllerapi.InferenceRunSpec
+		fields []string
+	}{
+		"valid parameters": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs", "length": "64", "region": "eu"},
+			},
+		},
+		"default used": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs"},
+			},
+		},
+		"required parameter missing": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"length": "64"},
+			},
+			fields: []string{"spec.parameters"},
+		},
+		"required parameter from a template": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs_{{ .RunName }}"},
+			},
+		},
+		"required parameter from a Secret": {
+			spec: controllerapi.InferenceRunSpec{
+				ParametersFrom: []controllerapi.ParameterSource{
+					{Name: "table", SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "tables"}, Key: "table"}},
+				},
+			},
+		},
+		"undeclared parameter": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs", "other": "value"},
+			},
+			fields: []string{"spec.parameters"},
+		},
+		"invalid values": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs", "length": "long", "region": "asia"},
+			},
+			fields: []string{"spec.parameters", "spec.parameters"},
+		},
+		"matrix values checked against the schema": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs"},
+				Matrix:     &controllerapi.MatrixSpec{Parameters: map[string][]string{"region": {"eu", "asia"}}},
+			},
+			fields: []string{"spec.matrix.parameters[region][1]"},
+		},
+		"undeclared matrix parameter": {
+			spec: controllerapi.InferenceRunSpec{
+				Parameters: &map[string]string{"table": "costs"},
+				Matrix:     &controllerapi.MatrixSpec{Parameters: map[string][]string{"model": {"a"}}},
+			},
+			fields: []string{"spec.parameters"},
+		},
+		"required parameter set by the matrix": {
+			spec: controllerapi.InferenceRunSpec{
+				Matrix: &controllerapi.MatrixSpec{Parameters: map[string][]string{"table": {"costs", "usage"}}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := validateRunParameters(&controllerapi.InferenceRun{Spec: tc.spec}, iConf)
+			assertFieldErrors(t, errs, tc.fields)
+		})
+	}
+
+	t.Run("no schema", func(t *testing.T) {
+		iRun := &controllerapi.InferenceRun{Spec: controllerapi.InferenceRunSpec{Parameters: &map[string]string{"anything": "value"}}}
+		if errs := validateRunParameters(iRun, &controllerapi.InferenceConfig{}); len(errs) != 0 {
+			t.Errorf("expected no errors without schema, got %v", errs)
+		}
+	})
+}
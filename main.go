@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
@@ -31,8 +32,11 @@ import (
 	"github.com/krateoplatformops/provider-runtime/pkg/ratelimiter"
 
 	controllerapi "kserve-controller/api/v1"
+	controllerapiv1beta2 "kserve-controller/api/v1beta2"
 	kservecontroller "kserve-controller/internal/controller"
 	"kserve-controller/internal/helpers/config"
+	"kserve-controller/internal/helpers/tracing"
+	kservewebhook "kserve-controller/internal/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -41,9 +45,12 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+const webhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(controllerapi.AddToScheme(scheme))
+	utilruntime.Must(controllerapiv1beta2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -110,6 +117,7 @@ func main() {
 
 	webhookServer := webhook.NewServer(webhook.Options{
 		TLSOpts: tlsOpts,
+		CertDir: webhookCertDir,
 	})
 
 	watchNamespace := configuration.WatchNamespace
@@ -166,6 +174,22 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "CompositionReference")
 		os.Exit(1)
 	}
+	if err := kservecontroller.SetupPipeline(mgr, o, configuration); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "InferencePipeline")
+		os.Exit(1)
+	}
+	if configuration.EnableWebhooks {
+		if err := kservewebhook.SetupInferenceConfigWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "InferenceConfig")
+			os.Exit(1)
+		}
+		if err := kservewebhook.SetupInferenceRunWebhookWithManager(mgr, configuration); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "InferenceRun")
+			os.Exit(1)
+		}
+		// The conversion webhook is registered with the other webhooks of the hub types, the Helm chart configures the
+		// CRDs to call it
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -177,9 +201,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	shutdownTracing := func(context.Context) error { return nil }
+	if configuration.EnableTracing {
+		shutdownTracing, err = tracing.Setup(context.Background())
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		setupLog.Error(err, "problem running manager")
+	startErr := mgr.Start(ctrl.SetupSignalHandler())
+	// The traces are flushed before exiting, os.Exit does not run deferred functions
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "unable to flush traces")
+	}
+	if startErr != nil {
+		setupLog.Error(startErr, "problem running manager")
 		os.Exit(1)
 	}
 }
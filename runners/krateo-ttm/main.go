@@ -9,152 +9,12 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/krateoplatformops/plumbing/endpoints"
-	"github.com/krateoplatformops/plumbing/http/request"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/rest"
-
-	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+	sdk "kserve-runner-sdk"
 )
 
-type ContractSpec struct {
-	JobId      string             `json:"jobId,omitempty"`
-	JobName    string             `json:"jobName,omitempty"`
-	KServe     KServeSpec         `json:"kserve"`
-	Input      StorageMap         `json:"input,omitempty"`
-	Output     StorageMap         `json:"output,omitempty"`
-	Parameters *map[string]string `json:"parameters,omitempty"`
-}
-
-type KServeSpec struct {
-	ModelName      string `json:"modelName,omitempty"`
-	ModelUrl       string `json:"modelUrl,omitempty"`
-	ModelVersion   string `json:"modelVersion,omitempty"`
-	ModelInputName string `json:"modelInputName,omitempty"`
-}
-
-type StorageMap map[string]runtime.RawExtension
-
-type KrateoStorage struct {
-	Api finopsdatatypes.API `json:"api"`
-}
-
-func LoadInputData(contract ContractSpec) ([][]float32, error) {
-	var inputTemp KrateoStorage
-	if err := json.Unmarshal(contract.Input["krateo"].Raw, &inputTemp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal krateo storage: %w", err)
-	}
-	cfg, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, fmt.Errorf("could not get inClusterConfig: %v", err)
-	}
-	endpoint, err := endpoints.FromSecret(context.Background(), cfg, inputTemp.Api.EndpointRef.Name, inputTemp.Api.EndpointRef.Namespace)
-	if err != nil {
-		return nil, fmt.Errorf("could not get endpoint secret: %v", err)
-	}
-
-	input := request.RequestOptions{
-		RequestInfo: request.RequestInfo{
-			Path:    inputTemp.Api.Path,
-			Verb:    &inputTemp.Api.Verb,
-			Payload: &inputTemp.Api.Payload,
-			Headers: inputTemp.Api.Headers,
-		},
-		Endpoint: &endpoint,
-	}
-
-	var bodyData []byte
-	input.ResponseHandler = func(rc io.ReadCloser) error {
-		bodyData, _ = io.ReadAll(rc)
-		return nil
-	}
-
-	toSend := map[string]any{}
-	if contract.Parameters != nil {
-		for k, v := range *contract.Parameters {
-			toSend[k] = v
-		}
-	}
-
-	payload, err := json.Marshal(toSend)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %v", err)
-	}
-	payloadString := string(payload)
-	input.Payload = &payloadString
-
-	res := request.Do(context.Background(), input)
-	if res.Code < 200 || res.Code >= 300 {
-		return nil, fmt.Errorf("failed to load input data, status: %s", res.Status)
-	}
-
-	var inputPayload map[string][][]float32
-	err = json.Unmarshal(bodyData, &inputPayload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal input data: %w", err)
-	}
-	return inputPayload["result"], nil
-}
-
-func StoreOutputData(contract ContractSpec, toStore map[string][]float32) error {
-	var outputTemp KrateoStorage
-	if err := json.Unmarshal(contract.Output["krateo"].Raw, &outputTemp); err != nil {
-		return fmt.Errorf("failed to unmarshal krateo storage: %w", err)
-	}
-	cfg, err := rest.InClusterConfig()
-	if err != nil {
-		return fmt.Errorf("could not get inClusterConfig: %v", err)
-	}
-	endpoint, err := endpoints.FromSecret(context.Background(), cfg, outputTemp.Api.EndpointRef.Name, outputTemp.Api.EndpointRef.Namespace)
-	if err != nil {
-		return fmt.Errorf("could not get endpoint secret: %v", err)
-	}
-
-	output := request.RequestOptions{
-		RequestInfo: request.RequestInfo{
-			Path:    outputTemp.Api.Path,
-			Verb:    &outputTemp.Api.Verb,
-			Payload: &outputTemp.Api.Payload,
-			Headers: outputTemp.Api.Headers,
-		},
-		Endpoint: &endpoint,
-	}
-
-	toSend := map[string]any{
-		"job_uid": contract.JobId,
-		"pod_uid": os.Getenv("pod_uid"),
-	}
-	if preds, ok := toStore["predictions"]; ok {
-		b, err := json.Marshal(preds)
-		if err != nil {
-			return fmt.Errorf("failed to marshal predictions to string: %w", err)
-		}
-		toSend["predictions"] = string(b)
-	} else {
-		toSend["predictions"] = "[]"
-	}
-	if contract.Parameters != nil {
-		for k, v := range *contract.Parameters {
-			toSend[k] = v
-		}
-	}
-
-	payload, err := json.Marshal(toSend)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %v", err)
-	}
-	payloadString := string(payload)
-	output.Payload = &payloadString
-
-	res := request.Do(context.Background(), output)
-	if res.Code < 200 || res.Code >= 300 {
-		return fmt.Errorf("failed to store data, status code: %d", res.Code)
-	}
-	return nil
-}
-
-func runInferenceV2(contract ContractSpec, payload [][]float32) (map[string][]float32, error) {
+func runInferenceV2(ctx context.Context, contract sdk.ContractSpec, payload [][]float32) (map[string][]float32, error) {
 	normalizedURL, err := normalizeURL(contract.KServe.ModelUrl)
 	if err != nil {
 		return nil, err
@@ -177,7 +37,8 @@ func runInferenceV2(contract ContractSpec, payload [][]float32) (map[string][]fl
 		return nil, err
 	}
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		normalizedURL,
 		bytes.NewBuffer(bodyBytes),
@@ -187,6 +48,8 @@ func runInferenceV2(contract ContractSpec, payload [][]float32) (map[string][]fl
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	// The spans of KServe are children of the span of the runner
+	sdk.InjectTraceHeaders(ctx, req.Header)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -226,44 +89,66 @@ func runInferenceV2(contract ContractSpec, payload [][]float32) (map[string][]fl
 }
 
 func main() {
-	contractPath := "/tmp/contract.json"
-
-	contractBytes, err := os.ReadFile(contractPath)
+	contract, contractBytes, err := sdk.LoadContract(sdk.ContractPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to read contract file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(2)
 	}
 
 	fmt.Fprintf(os.Stdout, "Contract: %s\n", string(contractBytes))
 
-	var contract ContractSpec
-	if err := json.Unmarshal(contractBytes, &contract); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to parse contract: %v\n", err)
-		os.Exit(2)
+	ctx, shutdownTracing, err := sdk.StartTracing(context.Background(), contract)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to set up tracing: %v\n", err)
+	}
+	// Spans are flushed before exiting, deferred functions do not run with os.Exit
+	exit := func(code int) {
+		if err := shutdownTracing(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to flush traces: %v\n", err)
+		}
+		os.Exit(code)
 	}
 
 	if contract.KServe.ModelUrl == "" {
 		fmt.Fprintln(os.Stderr, "kserve.url is required")
-		os.Exit(2)
+		exit(2)
 	}
 
-	inputPayload, err := LoadInputData(contract)
+	_, endSpan := sdk.StartSpan(ctx, "LoadInputData")
+	input, err := sdk.LoadInputData(contract)
+	endSpan(err)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load input data: %v\n", err)
-		os.Exit(3)
+		exit(3)
+	}
+	if len(input.Data) == 0 {
+		// Shards of Indexed Jobs can be empty when there are less rows than shards
+		fmt.Fprintln(os.Stdout, "no input rows to process")
+		exit(0)
 	}
 
-	result, err := runInferenceV2(contract, inputPayload)
+	inferenceCtx, endSpan := sdk.StartSpan(ctx, "runInferenceV2")
+	start := time.Now()
+	result, err := runInferenceV2(inferenceCtx, contract, input.Data)
+	sdk.Metrics.ObserveInference(time.Since(start))
+	endSpan(err)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "inference error: %v\n", err)
-		os.Exit(4)
+		exit(4)
 	}
 
-	if err := StoreOutputData(contract, result); err != nil {
+	_, endSpan = sdk.StartSpan(ctx, "StoreOutputData")
+	err = sdk.StoreOutputData(contract, input, result)
+	endSpan(err)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to store output: %v\n", err)
-		os.Exit(5)
+		exit(5)
+	}
+
+	if err := sdk.WriteResultSummary(input, result); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write termination message: %v\n", err)
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 func normalizeURL(raw string) (string, error) {
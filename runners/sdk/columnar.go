@@ -0,0 +1,83 @@
+// This file encodes the output rows in the columnar formats (Arrow IPC stream and Parquet)
+
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+var rowSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "job_uid", Type: arrow.BinaryTypes.String},
+	{Name: "pod_uid", Type: arrow.BinaryTypes.String},
+	{Name: "row", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "step", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "key", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "timestamp", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "prediction", Type: arrow.PrimitiveTypes.Float32},
+}, nil)
+
+func buildRecord(rows []Row) arrow.Record {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, rowSchema)
+	defer b.Release()
+
+	appendNullable := func(sb *array.StringBuilder, v string) {
+		if v == "" {
+			sb.AppendNull()
+		} else {
+			sb.Append(v)
+		}
+	}
+	for _, r := range rows {
+		b.Field(0).(*array.StringBuilder).Append(r.JobUid)
+		b.Field(1).(*array.StringBuilder).Append(r.PodUid)
+		b.Field(2).(*array.Int64Builder).Append(r.Row)
+		b.Field(3).(*array.Int64Builder).Append(r.Step)
+		appendNullable(b.Field(4).(*array.StringBuilder), r.Key)
+		appendNullable(b.Field(5).(*array.StringBuilder), r.Timestamp)
+		b.Field(6).(*array.Float32Builder).Append(r.Prediction)
+	}
+	return b.NewRecord()
+}
+
+func encodeArrow(rows []Row) ([]byte, error) {
+	rec := buildRecord(rows)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(rowSchema))
+	if err := w.Write(rec); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("failed to write arrow record: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close arrow writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeParquet(rows []Row) ([]byte, error) {
+	rec := buildRecord(rows)
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w, err := pqarrow.NewFileWriter(rowSchema, &buf, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	if err := w.Write(rec); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("failed to write parquet record: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close parquet writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
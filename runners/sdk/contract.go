@@ -0,0 +1,137 @@
+// Package sdk contains the building blocks shared by the runners: the contract passed by the controller,
+// the storage providers used to load and store data and the encoders for the output formats.
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Path where the controller mounts the contract of the runner
+const ContractPath = "/tmp/contract.json"
+
+type ContractSpec struct {
+	JobId        string             `json:"jobId,omitempty"`
+	JobName      string             `json:"jobName,omitempty"`
+	RunName      string             `json:"runName,omitempty"`
+	Namespace    string             `json:"namespace,omitempty"`
+	KServe       KServeSpec         `json:"kserve"`
+	Input        StorageMap         `json:"input,omitempty"`
+	Output       StorageMap         `json:"output,omitempty"`
+	OutputFormat OutputFormat       `json:"outputFormat,omitempty"`
+	Parameters   *map[string]string `json:"parameters,omitempty"`
+	// Rendered in Parameters by LoadContract, see RenderParameters
+	ParameterTemplates map[string]string `json:"parameterTemplates,omitempty"`
+	// Environment variables of the parameters set from Secrets, read by LoadContract, see GetSecretParameter
+	SecretParameters map[string]string `json:"secretParameters,omitempty"`
+	Secrets          *ContractSecrets  `json:"secrets,omitempty"`
+	// Only set for backfill executions, see GetExecutionTime
+	ExecutionTime *time.Time `json:"executionTime,omitempty"`
+	WindowStart   *time.Time `json:"windowStart,omitempty"`
+	WindowEnd     *time.Time `json:"windowEnd,omitempty"`
+	// Only set for Indexed Jobs, see GetShard
+	ShardCount int32 `json:"shardCount,omitempty"`
+	// Only set when tracing is enabled in the controller, see StartTracing
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+
+	// Values of the SecretParameters, kept out of Parameters so that they are never sent to the storage
+	secretValues map[string]string
+}
+
+// ContractSecrets lists the environment variables and secrets injected by the controller in the runner container.
+// Only names are passed in the contract, the values are read from the environment and the mounted files.
+type ContractSecrets struct {
+	Env     []string              `json:"env,omitempty"`
+	EnvFrom []ContractEnvFrom     `json:"envFrom,omitempty"`
+	Mounts  []ContractSecretMount `json:"mounts,omitempty"`
+}
+
+type ContractEnvFrom struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+type ContractSecretMount struct {
+	SecretName string `json:"secretName"`
+	MountPath  string `json:"mountPath"`
+}
+
+type KServeSpec struct {
+	ModelName      string `json:"modelName,omitempty"`
+	ModelUrl       string `json:"modelUrl,omitempty"`
+	ModelVersion   string `json:"modelVersion,omitempty"`
+	ModelInputName string `json:"modelInputName,omitempty"`
+}
+
+type StorageMap map[string]runtime.RawExtension
+
+func LoadContract(path string) (ContractSpec, []byte, error) {
+	var contract ContractSpec
+	contractBytes, err := os.ReadFile(path)
+	if err != nil {
+		return contract, nil, fmt.Errorf("failed to read contract file: %w", err)
+	}
+	if err := json.Unmarshal(contractBytes, &contract); err != nil {
+		return contract, contractBytes, fmt.Errorf("failed to parse contract: %w", err)
+	}
+	if err := contract.RenderParameters(); err != nil {
+		return contract, contractBytes, err
+	}
+	return contract, contractBytes, nil
+}
+
+// GetSecretParameter returns the value of a parameter set from a Secret. Secret parameters are only available to the
+// runner code: they are not in Parameters and are not sent to the input and output storage.
+func (c ContractSpec) GetSecretParameter(name string) (string, bool) {
+	value, ok := c.secretValues[name]
+	return value, ok
+}
+
+// ReadSecret reads the key of a secret mounted in the runner container
+func (c ContractSpec) ReadSecret(secretName, key string) ([]byte, error) {
+	if c.Secrets != nil {
+		for _, mount := range c.Secrets.Mounts {
+			if mount.SecretName == secretName {
+				return os.ReadFile(filepath.Join(mount.MountPath, key))
+			}
+		}
+	}
+	return nil, fmt.Errorf("secret %s is not mounted in the runner", secretName)
+}
+
+// GetExecutionTime returns the logical time of the execution: the end of the window for backfill executions,
+// the current time otherwise
+func (c ContractSpec) GetExecutionTime() time.Time {
+	if c.ExecutionTime != nil {
+		return *c.ExecutionTime
+	}
+	return time.Now().UTC()
+}
+
+// GetShard returns the index of the shard processed by the pod and the number of shards, 0 and 1 if the Job is not
+// sharded. Kubernetes sets the index of Indexed Jobs in the JOB_COMPLETION_INDEX environment variable: when it is
+// missing or out of range, an error is returned instead of processing the whole input in every pod.
+func (c ContractSpec) GetShard() (int, int, error) {
+	if c.ShardCount <= 1 {
+		return 0, 1, nil
+	}
+	value, ok := os.LookupEnv("JOB_COMPLETION_INDEX")
+	if !ok {
+		return 0, 0, fmt.Errorf("JOB_COMPLETION_INDEX is not set in a Job with %d shards", c.ShardCount)
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid JOB_COMPLETION_INDEX %q: %w", value, err)
+	}
+	if index < 0 || index >= int(c.ShardCount) {
+		return 0, 0, fmt.Errorf("JOB_COMPLETION_INDEX %d is out of the range of the %d shards", index, c.ShardCount)
+	}
+	return index, int(c.ShardCount), nil
+}
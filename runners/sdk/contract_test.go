@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetShard(t *testing.T) {
+	tests := map[string]struct {
+		shardCount int32
+		index      *string
+		wantIndex  int
+		wantCount  int
+		wantErr    bool
+	}{
+		"not sharded":              {wantIndex: 0, wantCount: 1},
+		"not sharded with index":   {shardCount: 1, index: ptr("3"), wantIndex: 0, wantCount: 1},
+		"shard":                    {shardCount: 4, index: ptr("2"), wantIndex: 2, wantCount: 4},
+		"last shard":               {shardCount: 4, index: ptr("3"), wantIndex: 3, wantCount: 4},
+		"missing index":            {shardCount: 4, wantErr: true},
+		"invalid index":            {shardCount: 4, index: ptr("two"), wantErr: true},
+		"index out of range":       {shardCount: 4, index: ptr("4"), wantErr: true},
+		"negative index":           {shardCount: 4, index: ptr("-1"), wantErr: true},
+		"empty index when sharded": {shardCount: 4, index: ptr(""), wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.index != nil {
+				t.Setenv("JOB_COMPLETION_INDEX", *tc.index)
+			} else {
+				// t.Setenv restores the variable at the end of the test
+				t.Setenv("JOB_COMPLETION_INDEX", "")
+				os.Unsetenv("JOB_COMPLETION_INDEX")
+			}
+			index, count, err := ContractSpec{ShardCount: tc.shardCount}.GetShard()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got shard %d of %d", index, count)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if index != tc.wantIndex || count != tc.wantCount {
+				t.Errorf("expected shard %d of %d, got %d of %d", tc.wantIndex, tc.wantCount, index, count)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,292 @@
+// This file handles connections to the finops-database-handler
+
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/krateoplatformops/plumbing/endpoints"
+	"github.com/krateoplatformops/plumbing/http/request"
+	"k8s.io/client-go/rest"
+
+	finopsdatatypes "github.com/krateoplatformops/finops-data-types/api/v1"
+
+	"kserve-runner-sdk/mapping"
+)
+
+const KrateoStorageLabel = "krateo"
+
+// ShardOffsetKey is returned by the input notebooks that only select the rows of the shard in shard_index and
+// shard_count, with the index of the first row of the shard in the whole input
+const ShardOffsetKey = "shard_offset"
+
+type KrateoStorage struct {
+	Api     finopsdatatypes.API `json:"api"`
+	Mapping *KrateoMapping      `json:"mapping,omitempty"`
+}
+
+// KrateoMapping describes how the payloads exchanged with the finops-database-handler are mapped, see the mapping
+// package
+type KrateoMapping = mapping.KrateoMapping
+
+// InputData is the data loaded from the input storage.
+// Keys and Timestamps are optional and are used to join the predictions back to the input rows.
+type InputData struct {
+	Data       [][]float32
+	Keys       []string
+	Timestamps []string
+	// Index of the first row in the whole input, for sharded Jobs
+	Offset int
+}
+
+// Partition returns the contiguous block of rows of the shard with the given index
+func (in *InputData) Partition(index int, count int) *InputData {
+	rows := len(in.Data)
+	if count <= 1 || rows == 0 {
+		return in
+	}
+	start, end := index*rows/count, (index+1)*rows/count
+	out := &InputData{
+		Data:   in.Data[start:end],
+		Offset: in.Offset + start,
+	}
+	if len(in.Keys) == rows {
+		out.Keys = in.Keys[start:end]
+	}
+	// Timestamps are either one per row or one per step of each row
+	if len(in.Timestamps) > 0 && len(in.Timestamps)%rows == 0 {
+		perRow := len(in.Timestamps) / rows
+		out.Timestamps = in.Timestamps[start*perRow : end*perRow]
+	}
+	return out
+}
+
+func LoadInputData(contract ContractSpec) (*InputData, error) {
+	var inputTemp KrateoStorage
+	if err := json.Unmarshal(contract.Input[KrateoStorageLabel].Raw, &inputTemp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal krateo storage: %w", err)
+	}
+
+	index, count, err := contract.GetShard()
+	if err != nil {
+		return nil, err
+	}
+	toSend := map[string]any{}
+	addParameters(contract, toSend)
+	addWindow(contract, toSend)
+	addShard(index, count, toSend)
+
+	start := time.Now()
+	bodyData, _, err := callKrateo(inputTemp.Api, toSend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load input data: %w", err)
+	}
+	fetchDuration := time.Since(start)
+
+	var inputPayload map[string]json.RawMessage
+	err = json.Unmarshal(bodyData, &inputPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal input data: %w", err)
+	}
+
+	resultKey := inputTemp.Mapping.GetResultKey()
+	result, ok := inputPayload[resultKey]
+	if !ok {
+		return nil, fmt.Errorf("input data does not contain key %s", resultKey)
+	}
+	input := &InputData{}
+	if err := json.Unmarshal(result, &input.Data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s from input data: %w", resultKey, err)
+	}
+	if keys, ok := inputPayload[inputTemp.Mapping.GetKeysKey()]; ok {
+		input.Keys, err = toStrings(keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s from input data: %w", inputTemp.Mapping.GetKeysKey(), err)
+		}
+	}
+	if timestamps, ok := inputPayload[inputTemp.Mapping.GetTimestampsKey()]; ok {
+		input.Timestamps, err = toStrings(timestamps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s from input data: %w", inputTemp.Mapping.GetTimestampsKey(), err)
+		}
+	}
+	// Each pod of an Indexed Job only processes its shard. Input notebooks that select the rows of the shard return the
+	// index of its first row in the whole input, otherwise the shard is taken from the whole input.
+	if offset, ok := inputPayload[ShardOffsetKey]; ok && count > 1 {
+		if err := json.Unmarshal(offset, &input.Offset); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s from input data: %w", ShardOffsetKey, err)
+		}
+	} else {
+		input = input.Partition(index, count)
+	}
+	Metrics.observeInput(fetchDuration, len(input.Data), len(bodyData))
+	return input, nil
+}
+
+func StoreOutputData(contract ContractSpec, input *InputData, toStore map[string][]float32) error {
+	var outputTemp KrateoStorage
+	if err := json.Unmarshal(contract.Output[KrateoStorageLabel].Raw, &outputTemp); err != nil {
+		return fmt.Errorf("failed to unmarshal krateo storage: %w", err)
+	}
+
+	toSend, err := buildOutputPayload(contract, outputTemp.Mapping, input, toStore)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, sent, err := callKrateo(outputTemp.Api, toSend)
+	if err != nil {
+		return fmt.Errorf("failed to store data: %w", err)
+	}
+	Metrics.observeOutput(time.Since(start), len(toStore["predictions"]), sent)
+	return nil
+}
+
+// buildOutputPayload returns the payload sent to the output storage: the predictions encoded in the output format,
+// the parameters, the window and the shard of the execution and the static fields of the mapping
+func buildOutputPayload(contract ContractSpec, mapping *KrateoMapping, input *InputData, toStore map[string][]float32) (map[string]any, error) {
+	toSend := map[string]any{
+		"job_uid": contract.JobId,
+		"pod_uid": os.Getenv("pod_uid"),
+	}
+	preds, ok := toStore["predictions"]
+	if !ok {
+		preds = []float32{}
+	}
+	predictionsKey := mapping.GetPredictionsKey()
+	switch format := contract.OutputFormat.OrDefault(); format {
+	case OutputFormatJSON:
+		if mapping.GetStringify() {
+			b, err := json.Marshal(preds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal predictions to string: %w", err)
+			}
+			toSend[predictionsKey] = string(b)
+		} else {
+			toSend[predictionsKey] = preds
+		}
+	default:
+		rows, err := BuildRows(contract.JobId, os.Getenv("pod_uid"), input, preds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build output rows: %w", err)
+		}
+		encoded, err := EncodeOutput(format, rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode predictions: %w", err)
+		}
+		if format.IsBinary() {
+			toSend[predictionsKey] = base64.StdEncoding.EncodeToString(encoded)
+		} else {
+			toSend[predictionsKey] = string(encoded)
+		}
+		toSend["output_format"] = string(format)
+	}
+	addParameters(contract, toSend)
+	addWindow(contract, toSend)
+	index, count, err := contract.GetShard()
+	if err != nil {
+		return nil, err
+	}
+	addShard(index, count, toSend)
+	if mapping != nil {
+		for k, v := range mapping.StaticFields {
+			toSend[k] = v
+		}
+	}
+
+	return toSend, nil
+}
+
+// callKrateo sends the payload to the API and returns the body of the response and the size of the payload
+func callKrateo(api finopsdatatypes.API, toSend map[string]any) ([]byte, int, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not get inClusterConfig: %v", err)
+	}
+	endpoint, err := endpoints.FromSecret(context.Background(), cfg, api.EndpointRef.Name, api.EndpointRef.Namespace)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not get endpoint secret: %v", err)
+	}
+
+	payload, err := json.Marshal(toSend)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	payloadString := string(payload)
+
+	opts := request.RequestOptions{
+		RequestInfo: request.RequestInfo{
+			Path:    api.Path,
+			Verb:    &api.Verb,
+			Payload: &payloadString,
+			Headers: api.Headers,
+		},
+		Endpoint: &endpoint,
+	}
+
+	var bodyData []byte
+	opts.ResponseHandler = func(rc io.ReadCloser) error {
+		bodyData, _ = io.ReadAll(rc)
+		return nil
+	}
+
+	res := request.Do(context.Background(), opts)
+	if res.Code < 200 || res.Code >= 300 {
+		return nil, 0, fmt.Errorf("status code: %d", res.Code)
+	}
+	return bodyData, len(payload), nil
+}
+
+// addParameters adds the parameters of the execution to the payload. The parameters set from Secrets are never sent
+func addParameters(contract ContractSpec, toSend map[string]any) {
+	if contract.Parameters == nil {
+		return
+	}
+	for k, v := range *contract.Parameters {
+		if _, ok := contract.SecretParameters[k]; !ok {
+			toSend[k] = v
+		}
+	}
+}
+
+// addShard passes the shard processed by the pod of Indexed Jobs to the notebooks
+func addShard(index, count int, toSend map[string]any) {
+	if count > 1 {
+		toSend["shard_index"] = index
+		toSend["shard_count"] = count
+	}
+}
+
+// addWindow passes the window of backfill executions to the notebooks, to select and tag the data
+func addWindow(contract ContractSpec, toSend map[string]any) {
+	if contract.WindowStart == nil || contract.WindowEnd == nil {
+		return
+	}
+	toSend["execution_time"] = contract.GetExecutionTime().Format(time.RFC3339)
+	toSend["window_start"] = contract.WindowStart.Format(time.RFC3339)
+	toSend["window_end"] = contract.WindowEnd.Format(time.RFC3339)
+}
+
+// toStrings converts a JSON array of strings or numbers to a slice of strings
+func toStrings(raw json.RawMessage) ([]string, error) {
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package sdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildOutputPayloadSecretParameters(t *testing.T) {
+	const secret = "s3cr3t-t0k3n"
+	t.Setenv("PARAMETER_TOKEN", secret)
+
+	tests := map[string]struct {
+		format OutputFormat
+	}{
+		"JSON": {format: OutputFormatJSON},
+		"CSV":  {format: OutputFormatCSV},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			contract := ContractSpec{
+				JobId:              "job",
+				OutputFormat:       tc.format,
+				Parameters:         &map[string]string{"table": "costs"},
+				ParameterTemplates: map[string]string{"run": "{{ .RunName }}"},
+				SecretParameters:   map[string]string{"token": "PARAMETER_TOKEN"},
+				RunName:            "forecast",
+			}
+			if err := contract.RenderParameters(); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := (*contract.Parameters)["token"]; ok {
+				t.Error("secret parameter added to the parameters")
+			}
+			if value, ok := contract.GetSecretParameter("token"); !ok || value != secret {
+				t.Errorf("expected the secret parameter to be readable by the runner, got %q", value)
+			}
+
+			toSend, err := buildOutputPayload(contract, nil, &InputData{Data: [][]float32{{0}}}, map[string][]float32{"predictions": {1, 2}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if toSend["table"] != "costs" || toSend["run"] != "forecast" {
+				t.Errorf("expected the parameters in the payload, got %v", toSend)
+			}
+			if _, ok := toSend["token"]; ok {
+				t.Error("secret parameter sent in the output payload")
+			}
+			payload, err := json.Marshal(toSend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(payload), secret) {
+				t.Errorf("secret value found in the output payload: %s", payload)
+			}
+		})
+	}
+}
+
+func TestPartition(t *testing.T) {
+	input := &InputData{
+		Data:       [][]float32{{0}, {1}, {2}, {3}, {4}},
+		Keys:       []string{"a", "b", "c", "d", "e"},
+		Timestamps: []string{"t0", "t1", "t2", "t3", "t4"},
+	}
+
+	tests := map[string]struct {
+		input *InputData
+		index int
+		count int
+		want  *InputData
+	}{
+		"not sharded": {
+			input: input,
+			index: 0,
+			count: 1,
+			want:  input,
+		},
+		"first shard": {
+			input: input,
+			index: 0,
+			count: 2,
+			want:  &InputData{Data: [][]float32{{0}, {1}}, Keys: []string{"a", "b"}, Timestamps: []string{"t0", "t1"}},
+		},
+		"last shard takes the remainder": {
+			input: input,
+			index: 1,
+			count: 2,
+			want:  &InputData{Data: [][]float32{{2}, {3}, {4}}, Keys: []string{"c", "d", "e"}, Timestamps: []string{"t2", "t3", "t4"}, Offset: 2},
+		},
+		"timestamps per step": {
+			input: &InputData{Data: [][]float32{{0}, {1}}, Timestamps: []string{"t0", "t1", "t2", "t3"}},
+			index: 1,
+			count: 2,
+			want:  &InputData{Data: [][]float32{{1}}, Timestamps: []string{"t2", "t3"}, Offset: 1},
+		},
+		"more shards than rows": {
+			input: &InputData{Data: [][]float32{{0}, {1}}},
+			index: 0,
+			count: 4,
+			want:  &InputData{Data: [][]float32{}},
+		},
+		"offset of the input kept": {
+			input: &InputData{Data: [][]float32{{0}, {1}}, Offset: 10},
+			index: 1,
+			count: 2,
+			want:  &InputData{Data: [][]float32{{1}}, Offset: 11},
+		},
+		"empty input": {
+			input: &InputData{},
+			index: 1,
+			count: 2,
+			want:  &InputData{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.input.Partition(tc.index, tc.count); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
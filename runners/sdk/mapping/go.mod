@@ -0,0 +1,3 @@
+module kserve-runner-sdk/mapping
+
+go 1.22
@@ -0,0 +1,63 @@
+// Package mapping describes how the runners map the payloads exchanged with the finops-database-handler. It has no
+// dependencies, so that the controller validates the krateo storage with the same type and defaults as the SDK.
+package mapping
+
+const (
+	DefaultResultKey      = "result"
+	DefaultKeysKey        = "keys"
+	DefaultTimestampsKey  = "timestamps"
+	DefaultPredictionsKey = "predictions"
+	DefaultStringify      = true
+)
+
+// KrateoMapping describes how the runner maps the payloads exchanged with the finops-database-handler.
+// ResultKey, KeysKey and TimestampsKey are only used by the input storage, the other fields are only used by the output storage.
+type KrateoMapping struct {
+	// Key of the input response that contains the data to send to the model (default: result)
+	ResultKey string `json:"resultKey,omitempty"`
+	// Key of the input response that contains the key of each input row, used by row-oriented output formats (default: keys)
+	KeysKey string `json:"keysKey,omitempty"`
+	// Key of the input response that contains the timestamps of the input rows or of the predictions (default: timestamps)
+	TimestampsKey string `json:"timestampsKey,omitempty"`
+	// Key of the output payload that contains the model predictions (default: predictions)
+	PredictionsKey string `json:"predictionsKey,omitempty"`
+	// If true, the predictions are sent as a JSON-encoded string instead of a JSON array (default: true)
+	Stringify *bool `json:"stringify,omitempty"`
+	// Additional fields added as is to the output payload
+	StaticFields map[string]string `json:"staticFields,omitempty"`
+}
+
+func (m *KrateoMapping) GetResultKey() string {
+	if m == nil || m.ResultKey == "" {
+		return DefaultResultKey
+	}
+	return m.ResultKey
+}
+
+func (m *KrateoMapping) GetKeysKey() string {
+	if m == nil || m.KeysKey == "" {
+		return DefaultKeysKey
+	}
+	return m.KeysKey
+}
+
+func (m *KrateoMapping) GetTimestampsKey() string {
+	if m == nil || m.TimestampsKey == "" {
+		return DefaultTimestampsKey
+	}
+	return m.TimestampsKey
+}
+
+func (m *KrateoMapping) GetPredictionsKey() string {
+	if m == nil || m.PredictionsKey == "" {
+		return DefaultPredictionsKey
+	}
+	return m.PredictionsKey
+}
+
+func (m *KrateoMapping) GetStringify() bool {
+	if m == nil || m.Stringify == nil {
+		return DefaultStringify
+	}
+	return *m.Stringify
+}
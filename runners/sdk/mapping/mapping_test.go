@@ -0,0 +1,48 @@
+package mapping
+
+import "testing"
+
+func TestKrateoMappingDefaults(t *testing.T) {
+	stringify := false
+	tests := map[string]struct {
+		mapping       *KrateoMapping
+		want          KrateoMapping
+		wantStringify bool
+	}{
+		"nil mapping": {
+			want:          KrateoMapping{ResultKey: DefaultResultKey, KeysKey: DefaultKeysKey, TimestampsKey: DefaultTimestampsKey, PredictionsKey: DefaultPredictionsKey},
+			wantStringify: DefaultStringify,
+		},
+		"empty mapping": {
+			mapping:       &KrateoMapping{},
+			want:          KrateoMapping{ResultKey: DefaultResultKey, KeysKey: DefaultKeysKey, TimestampsKey: DefaultTimestampsKey, PredictionsKey: DefaultPredictionsKey},
+			wantStringify: DefaultStringify,
+		},
+		"overridden": {
+			mapping:       &KrateoMapping{ResultKey: "data", KeysKey: "ids", TimestampsKey: "dates", PredictionsKey: "forecast", Stringify: &stringify},
+			want:          KrateoMapping{ResultKey: "data", KeysKey: "ids", TimestampsKey: "dates", PredictionsKey: "forecast"},
+			wantStringify: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := tc.mapping
+			if got := m.GetResultKey(); got != tc.want.ResultKey {
+				t.Errorf("expected result key %q, got %q", tc.want.ResultKey, got)
+			}
+			if got := m.GetKeysKey(); got != tc.want.KeysKey {
+				t.Errorf("expected keys key %q, got %q", tc.want.KeysKey, got)
+			}
+			if got := m.GetTimestampsKey(); got != tc.want.TimestampsKey {
+				t.Errorf("expected timestamps key %q, got %q", tc.want.TimestampsKey, got)
+			}
+			if got := m.GetPredictionsKey(); got != tc.want.PredictionsKey {
+				t.Errorf("expected predictions key %q, got %q", tc.want.PredictionsKey, got)
+			}
+			if got := m.GetStringify(); got != tc.wantStringify {
+				t.Errorf("expected stringify %t, got %t", tc.wantStringify, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// This file defines the output formats supported by the runners
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type OutputFormat string
+
+const (
+	OutputFormatJSON    OutputFormat = "JSON"
+	OutputFormatCSV     OutputFormat = "CSV"
+	OutputFormatNDJSON  OutputFormat = "NDJSON"
+	OutputFormatParquet OutputFormat = "Parquet"
+	OutputFormatArrow   OutputFormat = "Arrow"
+)
+
+func (f OutputFormat) OrDefault() OutputFormat {
+	if f == "" {
+		return OutputFormatJSON
+	}
+	return f
+}
+
+// IsBinary reports whether the encoded output must be transported as base64
+func (f OutputFormat) IsBinary() bool {
+	return f == OutputFormatParquet || f == OutputFormatArrow
+}
+
+// Row is a single prediction joined back to the input row it was computed from
+type Row struct {
+	JobUid     string  `json:"job_uid"`
+	PodUid     string  `json:"pod_uid"`
+	Row        int64   `json:"row"`
+	Step       int64   `json:"step"`
+	Key        string  `json:"key,omitempty"`
+	Timestamp  string  `json:"timestamp,omitempty"`
+	Prediction float32 `json:"prediction"`
+}
+
+var rowColumns = []string{"job_uid", "pod_uid", "row", "step", "key", "timestamp", "prediction"}
+
+// BuildRows splits the flat predictions evenly across the input rows and fails if they cannot be split evenly.
+// Timestamps are matched per prediction when there is one for each prediction, per input row otherwise.
+func BuildRows(jobUid, podUid string, input *InputData, predictions []float32) ([]Row, error) {
+	inputRows := 1
+	if input != nil && len(input.Data) > 0 {
+		inputRows = len(input.Data)
+	}
+	if len(predictions)%inputRows != 0 {
+		return nil, fmt.Errorf("%d predictions cannot be split evenly across %d input rows", len(predictions), inputRows)
+	}
+	perRow := len(predictions) / inputRows
+
+	rows := make([]Row, 0, len(predictions))
+	for i, p := range predictions {
+		local := i / perRow
+		row := Row{
+			JobUid:     jobUid,
+			PodUid:     podUid,
+			Row:        int64(local),
+			Step:       int64(i % perRow),
+			Prediction: p,
+		}
+		if input != nil {
+			row.Row += int64(input.Offset)
+			if local < len(input.Keys) {
+				row.Key = input.Keys[local]
+			}
+			if len(input.Timestamps) == len(predictions) {
+				row.Timestamp = input.Timestamps[i]
+			} else if local < len(input.Timestamps) {
+				row.Timestamp = input.Timestamps[local]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func EncodeOutput(format OutputFormat, rows []Row) ([]byte, error) {
+	switch format.OrDefault() {
+	case OutputFormatJSON:
+		return json.Marshal(rows)
+	case OutputFormatCSV:
+		return encodeCSV(rows)
+	case OutputFormatNDJSON:
+		return encodeNDJSON(rows)
+	case OutputFormatParquet:
+		return encodeParquet(rows)
+	case OutputFormatArrow:
+		return encodeArrow(rows)
+	default:
+		return nil, fmt.Errorf("unsupported output format %s", format)
+	}
+}
+
+func encodeCSV(rows []Row) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(rowColumns); err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		record := []string{
+			r.JobUid,
+			r.PodUid,
+			strconv.FormatInt(r.Row, 10),
+			strconv.FormatInt(r.Step, 10),
+			r.Key,
+			r.Timestamp,
+			strconv.FormatFloat(float64(r.Prediction), 'g', -1, 32),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func encodeNDJSON(rows []Row) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,222 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+func TestBuildRows(t *testing.T) {
+	tests := map[string]struct {
+		input       *InputData
+		predictions []float32
+		want        []Row
+		wantErr     bool
+	}{
+		"no input": {
+			predictions: []float32{1, 2},
+			want: []Row{
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 0, Prediction: 1},
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 1, Prediction: 2},
+			},
+		},
+		"predictions split across rows with keys and timestamps per row": {
+			input: &InputData{
+				Data:       [][]float32{{0}, {0}},
+				Keys:       []string{"a", "b"},
+				Timestamps: []string{"t0", "t1"},
+			},
+			predictions: []float32{1, 2, 3, 4},
+			want: []Row{
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 0, Key: "a", Timestamp: "t0", Prediction: 1},
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 1, Key: "a", Timestamp: "t0", Prediction: 2},
+				{JobUid: "job", PodUid: "pod", Row: 1, Step: 0, Key: "b", Timestamp: "t1", Prediction: 3},
+				{JobUid: "job", PodUid: "pod", Row: 1, Step: 1, Key: "b", Timestamp: "t1", Prediction: 4},
+			},
+		},
+		"timestamps per prediction": {
+			input: &InputData{
+				Data:       [][]float32{{0}},
+				Timestamps: []string{"t0", "t1"},
+			},
+			predictions: []float32{1, 2},
+			want: []Row{
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 0, Timestamp: "t0", Prediction: 1},
+				{JobUid: "job", PodUid: "pod", Row: 0, Step: 1, Timestamp: "t1", Prediction: 2},
+			},
+		},
+		"offset of the shard": {
+			input:       &InputData{Data: [][]float32{{0}, {0}}, Offset: 10},
+			predictions: []float32{1, 2},
+			want: []Row{
+				{JobUid: "job", PodUid: "pod", Row: 10, Step: 0, Prediction: 1},
+				{JobUid: "job", PodUid: "pod", Row: 11, Step: 0, Prediction: 2},
+			},
+		},
+		"no predictions": {
+			input:       &InputData{Data: [][]float32{{0}, {0}}},
+			predictions: []float32{},
+			want:        []Row{},
+		},
+		"uneven split": {
+			input:       &InputData{Data: [][]float32{{0}, {0}}},
+			predictions: []float32{1, 2, 3},
+			wantErr:     true,
+		},
+		"fewer predictions than rows": {
+			input:       &InputData{Data: [][]float32{{0}, {0}, {0}}},
+			predictions: []float32{1, 2},
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rows, err := BuildRows("job", "pod", tc.input, tc.predictions)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got rows %v", rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, rows)
+			}
+		})
+	}
+}
+
+func TestEncodeOutput(t *testing.T) {
+	rows := []Row{
+		{JobUid: "job", PodUid: "pod", Row: 0, Step: 0, Key: "a", Timestamp: "t0", Prediction: 1.5},
+		{JobUid: "job", PodUid: "pod", Row: 1, Step: 0, Prediction: 2},
+	}
+
+	tests := map[string]struct {
+		format  OutputFormat
+		decode  func(t *testing.T, b []byte) int
+		wantErr bool
+	}{
+		"default": {
+			decode: decodeJSONRows,
+		},
+		"JSON": {
+			format: OutputFormatJSON,
+			decode: decodeJSONRows,
+		},
+		"CSV": {
+			format: OutputFormatCSV,
+			decode: func(t *testing.T, b []byte) int {
+				records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(records[0], rowColumns) {
+					t.Errorf("expected header %v, got %v", rowColumns, records[0])
+				}
+				if records[1][6] != "1.5" {
+					t.Errorf("expected prediction 1.5, got %s", records[1][6])
+				}
+				return len(records) - 1
+			},
+		},
+		"NDJSON": {
+			format: OutputFormatNDJSON,
+			decode: func(t *testing.T, b []byte) int {
+				lines := 0
+				scanner := bufio.NewScanner(bytes.NewReader(b))
+				for scanner.Scan() {
+					var row Row
+					if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+						t.Fatal(err)
+					}
+					if row != rows[lines] {
+						t.Errorf("expected %v, got %v", rows[lines], row)
+					}
+					lines++
+				}
+				return lines
+			},
+		},
+		"Arrow": {
+			format: OutputFormatArrow,
+			decode: func(t *testing.T, b []byte) int {
+				r, err := ipc.NewReader(bytes.NewReader(b))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer r.Release()
+				if !r.Schema().Equal(rowSchema) {
+					t.Errorf("expected schema %v, got %v", rowSchema, r.Schema())
+				}
+				count := 0
+				for r.Next() {
+					count += int(r.Record().NumRows())
+				}
+				return count
+			},
+		},
+		"Parquet": {
+			format: OutputFormatParquet,
+			decode: func(t *testing.T, b []byte) int {
+				pf, err := file.NewParquetReader(bytes.NewReader(b))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer pf.Close()
+				fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+				if err != nil {
+					t.Fatal(err)
+				}
+				table, err := fr.ReadTable(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer table.Release()
+				return int(table.NumRows())
+			},
+		},
+		"unsupported": {
+			format:  OutputFormat("XML"),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := EncodeOutput(tc.format, rows)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count := tc.decode(t, b); count != len(rows) {
+				t.Errorf("expected %d rows, got %d", len(rows), count)
+			}
+		})
+	}
+}
+
+func decodeJSONRows(t *testing.T, b []byte) int {
+	var decoded []Row
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return len(decoded)
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData are the variables available in the templates of the parameters, as in the controller
+type TemplateData struct {
+	ScheduledTime time.Time
+	RunName       string
+	Namespace     string
+	JobId         string
+	JobName       string
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"add": func(duration string, t time.Time) (time.Time, error) {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return t, err
+		}
+		return t.Add(d), nil
+	},
+	"addDays": func(days int, t time.Time) time.Time {
+		return t.AddDate(0, 0, days)
+	},
+}
+
+// RenderParameters adds to the parameters the templates rendered with the execution time, for CronJob executions,
+// and reads the values of the parameters set from Secrets from the environment, see GetSecretParameter
+func (c *ContractSpec) RenderParameters() error {
+	if len(c.SecretParameters) > 0 {
+		c.secretValues = make(map[string]string, len(c.SecretParameters))
+		for name, envName := range c.SecretParameters {
+			c.secretValues[name] = os.Getenv(envName)
+		}
+	}
+	if len(c.ParameterTemplates) == 0 {
+		return nil
+	}
+	params := map[string]string{}
+	if c.Parameters != nil {
+		for k, v := range *c.Parameters {
+			params[k] = v
+		}
+	}
+
+	data := TemplateData{
+		ScheduledTime: c.GetExecutionTime(),
+		RunName:       c.RunName,
+		Namespace:     c.Namespace,
+		JobId:         c.JobId,
+		JobName:       c.JobName,
+	}
+	for name, value := range c.ParameterTemplates {
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid template for parameter %s: %w", name, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return fmt.Errorf("could not render parameter %s: %w", name, err)
+		}
+		params[name] = out.String()
+	}
+
+	c.Parameters = &params
+	c.ParameterTemplates = nil
+	return nil
+}
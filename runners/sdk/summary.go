@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Path of the termination message of the runner container, reported by the controller in the execution history
+const TerminationMessagePath = "/dev/termination-log"
+
+// Maximum number of inference latencies reported, the termination message is limited to 4096 bytes
+const MaxInferenceSamples = 64
+
+// RunnerReport is written as termination message of the runner container. The controller reports the summary in the
+// execution history and exposes the metrics labelled by InferenceConfig, since runner pods are too short-lived to be
+// scraped.
+type RunnerReport struct {
+	Summary string        `json:"summary"`
+	Metrics RunnerMetrics `json:"metrics"`
+}
+
+type RunnerMetrics struct {
+	InputFetchSeconds  float64 `json:"inputFetchSeconds,omitempty"`
+	OutputWriteSeconds float64 `json:"outputWriteSeconds,omitempty"`
+	// Latency of each call to the model, up to MaxInferenceSamples
+	InferenceSeconds []float64 `json:"inferenceSeconds,omitempty"`
+	InputRows        int       `json:"inputRows,omitempty"`
+	Predictions      int       `json:"predictions,omitempty"`
+	InputBytes       int       `json:"inputBytes,omitempty"`
+	OutputBytes      int       `json:"outputBytes,omitempty"`
+}
+
+// Metrics collects the metrics of the execution. LoadInputData and StoreOutputData record the timings and sizes of
+// the storage calls, runners record the latency of the calls to the model with ObserveInference.
+var Metrics = &metricsCollector{}
+
+type metricsCollector struct {
+	mu      sync.Mutex
+	metrics RunnerMetrics
+}
+
+// ObserveInference records the latency of a call to the model
+func (c *metricsCollector) ObserveInference(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.metrics.InferenceSeconds) < MaxInferenceSamples {
+		c.metrics.InferenceSeconds = append(c.metrics.InferenceSeconds, d.Seconds())
+	}
+}
+
+func (c *metricsCollector) observeInput(d time.Duration, rows int, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics.InputFetchSeconds += d.Seconds()
+	c.metrics.InputRows += rows
+	c.metrics.InputBytes += bytes
+}
+
+func (c *metricsCollector) observeOutput(d time.Duration, predictions int, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics.OutputWriteSeconds += d.Seconds()
+	c.metrics.Predictions += predictions
+	c.metrics.OutputBytes += bytes
+}
+
+func (c *metricsCollector) snapshot() RunnerMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics := c.metrics
+	metrics.InferenceSeconds = append([]float64(nil), c.metrics.InferenceSeconds...)
+	return metrics
+}
+
+// WriteResultSummary writes a short summary of the stored predictions and the metrics of the execution as
+// termination message of the container
+func WriteResultSummary(input *InputData, toStore map[string][]float32) error {
+	predictions := 0
+	for _, values := range toStore {
+		predictions += len(values)
+	}
+	report := RunnerReport{
+		Summary: fmt.Sprintf("stored %d predictions for %d input rows", predictions, len(input.Data)),
+		Metrics: Metrics.snapshot(),
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal runner report: %w", err)
+	}
+	return os.WriteFile(TerminationMessagePath, b, 0644)
+}
@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const TracerName = "kserve-runner-sdk"
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// StartTracing returns a context in the trace of the InferenceRun, read from the trace context of the contract.
+// When the OTEL_EXPORTER_OTLP_ENDPOINT environment variable is set, the spans are exported through OTLP over HTTP;
+// otherwise spans are not recorded, but the trace context is still forwarded to KServe.
+// The returned function flushes the spans, it must be called before the runner exits.
+func StartTracing(ctx context.Context, contract ContractSpec) (context.Context, func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+	ctx = propagator.Extract(ctx, propagation.MapCarrier(contract.TraceContext))
+
+	shutdown := func(context.Context) error { return nil }
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return ctx, shutdown, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return ctx, shutdown, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName("kserve-runner"),
+			attribute.String("inferencerun.name", contract.RunName),
+			attribute.String("inferencerun.namespace", contract.Namespace),
+			attribute.String("job.name", contract.JobName),
+		),
+		resource.WithFromEnv())
+	if err != nil {
+		return ctx, shutdown, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return ctx, provider.Shutdown, nil
+}
+
+// StartSpan starts a span of the runner, the returned function ends it and records the error, if any
+func StartSpan(ctx context.Context, name string) (context.Context, func(err error)) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, name)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// InjectTraceHeaders sets the W3C trace context headers (traceparent) of the span in the context on the request,
+// so that the spans of KServe are children of the spans of the runner
+func InjectTraceHeaders(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}